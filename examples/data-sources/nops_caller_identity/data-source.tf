@@ -0,0 +1,10 @@
+data "nops_caller_identity" "this" {}
+
+output "nops_client_id" {
+  value = data.nops_caller_identity.this.client_id
+
+  precondition {
+    condition     = data.nops_caller_identity.this.client_id == var.expected_nops_client_id
+    error_message = "The nOps credentials belong to another nOps client."
+  }
+}
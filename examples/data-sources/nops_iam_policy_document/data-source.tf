@@ -0,0 +1,13 @@
+data "nops_iam_policy_document" "this" {
+  essentials      = true
+  compute_copilot = true
+  wafr            = true
+  system_bucket   = true
+  bucket_name     = "nops-1234-15418-471112641702"
+}
+
+resource "aws_iam_role_policy" "nops_integration_policy" {
+  name   = "NopsIntegrationPolicy"
+  role   = aws_iam_role.nops_integration_role.id
+  policy = data.nops_iam_policy_document.this.integration_json
+}
@@ -0,0 +1,4 @@
+resource "aws_iam_role" "nops_integration_role" {
+  name               = "NopsIntegrationRole-${nops_project.project.id}"
+  assume_role_policy = provider::nops::integration_trust_policy(nops_project.project.external_id)
+}
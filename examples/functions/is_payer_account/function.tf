@@ -0,0 +1,3 @@
+locals {
+  is_master_account = provider::nops::is_payer_account(data.aws_caller_identity.current.account_id, data.aws_organizations_organization.current.master_account_id)
+}
@@ -0,0 +1,3 @@
+locals {
+  system_bucket_name = provider::nops::system_bucket_name(nops_project.project.id, nops_project.project.client, data.aws_caller_identity.current.account_id, var.system_bucket_name)
+}
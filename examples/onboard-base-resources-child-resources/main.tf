@@ -1,6 +1,8 @@
 
 
 terraform {
+  # Provider-defined functions require Terraform 1.8 or later.
+  required_version = ">= 1.8"
   required_providers {
     nops = {
       source  = "terraform.local/custom/nops"
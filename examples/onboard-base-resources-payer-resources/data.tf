@@ -3,3 +3,11 @@
 data "aws_caller_identity" "current" {}
 
 data "aws_organizations_organization" "current" {}
+
+data "nops_iam_policy_document" "this" {
+  essentials      = var.essentials
+  compute_copilot = var.compute_copilot
+  wafr            = var.wafr
+  system_bucket   = local.is_master_account && local.system_bucket_name != "na"
+  bucket_name     = local.system_bucket_name
+}
@@ -12,14 +12,14 @@ provider "nops" {}
 
 resource "nops_project" "project" {
   name                        = "nops-provider"
-  account_number              = "xxxxxx"
-  master_payer_account_number = "xxxxxx"
+  account_number              = "123456789012"
+  master_payer_account_number = "123456789012"
 }
 
 resource "nops_integration" "integration" {
-  role_arn       = "arn:aws:iam::xxxxx:role/na"
-  external_id    = "NOPS-xxxxxx"
-  aws_account_id = "xxxx"
+  role_arn       = "arn:aws:iam::123456789012:role/nops-integration"
+  external_id    = "NOPS-123456"
+  aws_account_id = "123456789012"
   bucket_name    = "na"
   depends_on = [
     nops_project.project
@@ -0,0 +1,10 @@
+# Projects can be imported by their nOps project ID
+terraform import nops_project.project 12345
+
+# or by the AWS account number or name of the project, failing when several projects match
+terraform import nops_project.project account:123456789012
+terraform import nops_project.project name:prod-payer
+
+# nOps doesn't report the master payer of some projects, such as the ones it
+# discovered in an organization. The first plan after importing them updates
+# master_payer_account_number in place, which only records it in the state.
@@ -0,0 +1,143 @@
+package nops
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// DefaultTokenPath - path of the OAuth token endpoint on the nOps host.
+const DefaultTokenPath string = "/o/token/"
+
+// tokenExpiryDelta is how long before its expiry a token is refreshed, so it
+// doesn't expire while a request is in flight.
+const tokenExpiryDelta = time.Minute
+
+// oauthToken caches the bearer token obtained with the client credentials, or
+// the API key printed by the api_key_command.
+type oauthToken struct {
+	mu          sync.Mutex
+	accessToken string
+	expiry      time.Time
+}
+
+// tokenResponse - OAuth token endpoint response.
+type tokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int64  `json:"expires_in"`
+}
+
+// usesClientCredentials reports whether the OAuth client credentials flow is configured.
+func (a AuthStruct) usesClientCredentials() bool {
+	return a.ClientID != ""
+}
+
+// refreshable reports whether the credentials can be renewed after a 401.
+func (a AuthStruct) refreshable() bool {
+	return a.usesClientCredentials() || a.usesApiKeyCommand()
+}
+
+// authenticate sets the credential headers on the request.
+func (c *Client) authenticate(req *http.Request) error {
+	if c.Auth.usesApiKeyCommand() {
+		apiKey, err := c.commandApiKey(req.Context())
+		if err != nil {
+			return fmt.Errorf("obtaining nOps API key: %w", err)
+		}
+		req.Header.Set("X-Nops-Api-Key", apiKey)
+		return nil
+	}
+
+	if !c.Auth.usesClientCredentials() {
+		req.Header.Set("X-Nops-Api-Key", c.Auth.ApiKey)
+		return nil
+	}
+
+	token, err := c.accessToken(req.Context())
+	if err != nil {
+		return fmt.Errorf("obtaining nOps access token: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	return nil
+}
+
+// invalidateCredentials drops any cached short-lived credential so the next request renews it.
+func (c *Client) invalidateCredentials() {
+	c.token.mu.Lock()
+	c.token.accessToken = ""
+	c.token.mu.Unlock()
+}
+
+// accessToken returns the cached bearer token, exchanging the client
+// credentials for a new one when it is missing or about to expire.
+func (c *Client) accessToken(ctx context.Context) (string, error) {
+	c.token.mu.Lock()
+	defer c.token.mu.Unlock()
+
+	if c.token.accessToken != "" && time.Until(c.token.expiry) > tokenExpiryDelta {
+		return c.token.accessToken, nil
+	}
+
+	tokenURL := c.Auth.TokenURL
+	if tokenURL == "" {
+		tokenURL = strings.TrimSuffix(c.HostURL, "/") + DefaultTokenPath
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "client_credentials")
+
+	req, err := http.NewRequestWithContext(ctx, "POST", tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	c.setClientHeaders(req)
+	req.SetBasicAuth(url.QueryEscape(c.Auth.ClientID), url.QueryEscape(c.Auth.ClientSecret))
+
+	res, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return "", err
+	}
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return "", newAPIError(res, body)
+	}
+
+	token := tokenResponse{}
+	if err := json.Unmarshal(body, &token); err != nil {
+		return "", fmt.Errorf("decoding token response: %w", err)
+	}
+	if token.AccessToken == "" {
+		return "", errors.New("token response did not include an access_token")
+	}
+	if token.TokenType != "" && !strings.EqualFold(token.TokenType, "bearer") {
+		return "", fmt.Errorf("unsupported token type %q", token.TokenType)
+	}
+
+	c.token.accessToken = token.AccessToken
+	c.token.expiry = time.Now().Add(time.Duration(token.ExpiresIn) * time.Second)
+	if token.ExpiresIn <= 0 {
+		// No lifetime advertised, keep it until the API rejects it.
+		c.token.expiry = time.Now().Add(24 * time.Hour)
+	}
+	tflog.Debug(ctx, "Obtained nOps access token", map[string]any{"expires_in": token.ExpiresIn})
+
+	return c.token.accessToken, nil
+}
@@ -0,0 +1,147 @@
+package nops
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestClientCredentialsTokenIsCachedAndRefreshedOn401(t *testing.T) {
+	var issued atomic.Int32
+	var revoked atomic.Bool
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == DefaultTokenPath {
+			id, secret, ok := r.BasicAuth()
+			if !ok || id != "client" || secret != "secret" || r.FormValue("grant_type") != "client_credentials" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			fmt.Fprintf(w, `{"access_token": "token-%d", "token_type": "Bearer", "expires_in": 3600}`, issued.Add(1))
+			return
+		}
+
+		if r.Header.Get("X-Nops-Api-Key") != "" {
+			t.Errorf("the API key header must not be sent with OAuth credentials")
+		}
+		auth := r.Header.Get("Authorization")
+		if auth == "" || (revoked.Load() && auth == "Bearer token-1") {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(`[]`))
+	})
+	client.Auth = AuthStruct{ClientID: "client", ClientSecret: "secret"}
+	client.ProjectsCacheTTL = 0
+
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		if _, err := client.GetProjects(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := issued.Load(); got != 1 {
+		t.Fatalf("expected the token to be cached, %d were issued", got)
+	}
+
+	revoked.Store(true)
+	if _, err := client.GetProjects(ctx); err != nil {
+		t.Fatalf("expected the request to succeed after refreshing the token: %s", err)
+	}
+	if got := issued.Load(); got != 2 {
+		t.Fatalf("expected a single refresh, %d tokens were issued", got)
+	}
+}
+
+func TestClientCredentialsFailuresAreReported(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = w.Write([]byte(`{"error": "invalid_client"}`))
+	})
+	client.Auth = AuthStruct{ClientID: "client", ClientSecret: "wrong"}
+
+	_, err := client.GetProjects(context.Background())
+	if !IsUnauthorized(err) {
+		t.Fatalf("expected an unauthorized error, got %v", err)
+	}
+}
+
+func TestAPIKeyIsSentWithoutTokenExchange(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == DefaultTokenPath || r.Header.Get("X-Nops-Api-Key") != "test-key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(`[]`))
+	})
+
+	if _, err := client.GetProjects(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestApiKeyCommandIsCachedAndRerunOn401(t *testing.T) {
+	counter := filepath.Join(t.TempDir(), "runs")
+	script := `n=$(cat "$1" 2>/dev/null || echo 0); n=$((n+1)); echo $n > "$1"; printf '{"api_key": "key-%s"}' $n`
+
+	var revoked atomic.Bool
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get("X-Nops-Api-Key")
+		if key == "" || (revoked.Load() && key == "key-1") {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(`[]`))
+	})
+	client.Auth = AuthStruct{ApiKeyCommand: []string{"sh", "-c", script, "sh", counter}}
+	client.ProjectsCacheTTL = 0
+
+	runs := func() string {
+		raw, _ := os.ReadFile(counter)
+		return strings.TrimSpace(string(raw))
+	}
+
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		if _, err := client.GetProjects(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := runs(); got != "1" {
+		t.Fatalf("expected the API key to be cached, the command ran %s times", got)
+	}
+
+	revoked.Store(true)
+	if _, err := client.GetProjects(ctx); err != nil {
+		t.Fatalf("expected the request to succeed after rerunning the command: %s", err)
+	}
+	if got := runs(); got != "2" {
+		t.Fatalf("expected a single rerun, the command ran %s times", got)
+	}
+}
+
+func TestApiKeyCommandFailuresAreReported(t *testing.T) {
+	for name, script := range map[string]string{
+		"exit status": `echo "vault is sealed" >&2; exit 1`,
+		"not json":    `echo secret-key`,
+		"no key":      `echo '{"expiration": "2999-01-01T00:00:00Z"}'`,
+		"expired":     `echo '{"api_key": "key", "expiration": "2000-01-01T00:00:00Z"}'`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := runApiKeyCommand(context.Background(), []string{"sh", "-c", script})
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			if strings.Contains(err.Error(), "secret-key") {
+				t.Fatalf("the command output must not leak into errors: %s", err)
+			}
+			if name == "exit status" && !strings.Contains(err.Error(), "vault is sealed") {
+				t.Fatalf("expected stderr to be reported: %s", err)
+			}
+		})
+	}
+}
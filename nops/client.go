@@ -1,37 +1,96 @@
 package nops
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"math/rand/v2"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 )
 
 // HostURL - Default nOps URL.
 const HostURL string = "https://app.nops.io"
 
+// DefaultRequestTimeout - default time limit for a single API call.
+const DefaultRequestTimeout time.Duration = 10 * time.Second
+
+// Default retry settings, used unless the provider configuration overrides them.
+const (
+	DefaultMaxRetries   int           = 3
+	DefaultRetryWaitMin time.Duration = 1 * time.Second
+	DefaultRetryWaitMax time.Duration = 30 * time.Second
+)
+
 // Client - HTTP client to be used by the provider.
 type Client struct {
 	HostURL    string
 	HTTPClient *http.Client
 	Auth       AuthStruct
+	// MaxRetries is the number of extra attempts made for a retryable request
+	// after a throttled, 5xx or transport level failure.
+	MaxRetries int
+	// RetryWaitMin and RetryWaitMax bound the jittered exponential backoff
+	// between attempts, RetryWaitMax also caps any Retry-After sent by the API.
+	RetryWaitMin time.Duration
+	RetryWaitMax time.Duration
+	// ProjectsCacheTTL is how long GetProjects results are shared between
+	// callers, zero disables the cache.
+	ProjectsCacheTTL time.Duration
+	// UserAgent identifies the provider release, Terraform version and
+	// platform on every call, DefaultUserAgent when empty.
+	UserAgent string
+	// Caller, when set, is sent as the X-Nops-Caller header to tell apart the
+	// pipelines or teams sharing an API key.
+	Caller string
+	// Identity is the caller identity resolved when the provider validated
+	// its credentials, nil when they weren't validated.
+	Identity *CallerIdentity
+	// StrictProjectReads makes nops_project fail to refresh a project deleted
+	// outside of Terraform instead of removing it from state.
+	StrictProjectReads bool
+	// AdoptExistingProjects makes nops_project adopt an already integrated
+	// project of its account unless the resource sets adopt_existing.
+	AdoptExistingProjects bool
+
+	projectsCache projectsCache
+	rateLimiter   *rateLimiter
+	token         oauthToken
 }
 
-// AuthStruct - authentication mechanism with an API Key.
+// AuthStruct - authentication mechanism, either a static API Key or OAuth
+// client credentials exchanged for short-lived bearer tokens.
 type AuthStruct struct {
 	ApiKey string `json:"api_key"`
+
+	ClientID     string `json:"client_id"`
+	ClientSecret string `json:"client_secret"`
+	// TokenURL defaults to DefaultTokenPath on the client host.
+	TokenURL string `json:"token_url"`
+
+	// ApiKeyCommand is the program and arguments printing the API key, used instead of ApiKey.
+	ApiKeyCommand []string `json:"api_key_command"`
 }
 
 // NewClient - instantiates a client for the provider to use.
 func NewClient(host, api_key *string) (*Client, error) {
 	c := Client{
 		HTTPClient: &http.Client{
-			Timeout: 10 * time.Second,
+			Timeout: DefaultRequestTimeout,
 		},
 		// Default nOps URL
-		HostURL: HostURL,
+		HostURL:      HostURL,
+		MaxRetries:   DefaultMaxRetries,
+		RetryWaitMin: DefaultRetryWaitMin,
+		RetryWaitMax: DefaultRetryWaitMax,
+
+		ProjectsCacheTTL: DefaultProjectsCacheTTL,
 	}
 
 	if host != nil {
@@ -49,60 +108,180 @@ func NewClient(host, api_key *string) (*Client, error) {
 	return &c, nil
 }
 
+// doRequest sends the request, retrying it when the method is idempotent.
 func (c *Client) doRequest(req *http.Request) ([]byte, error) {
-	token := c.Auth.ApiKey
+	return c.do(req, isIdempotent(req.Method))
+}
+
+// doRetrySafeRequest sends a request that is safe to replay regardless of its
+// method, such as a POST notification the API treats as an upsert.
+func (c *Client) doRetrySafeRequest(req *http.Request) ([]byte, error) {
+	return c.do(req, true)
+}
 
-	req.Header.Set("X-Nops-Api-Key", token)
+func (c *Client) do(req *http.Request, retryable bool) ([]byte, error) {
 	req.Header.Set("Content-Type", "application/json")
+	c.setClientHeaders(req)
+
+	// The request context carries the caller's cancellation, deadline and tflog fields.
+	ctx := req.Context()
+	tflog.Debug(ctx, "Sending nOps API request", map[string]any{"method": req.Method, "path": req.URL.Path})
+
+	attempt := 0
+	reauthenticated := false
+	for sent := false; ; sent = true {
+		if sent && req.GetBody != nil {
+			reqBody, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req.Body = reqBody
+		}
+
+		if err := c.authenticate(req); err != nil {
+			return nil, err
+		}
+
+		if err := c.waitForRateLimit(ctx, req.URL.Path); err != nil {
+			return nil, err
+		}
+
+		body, res, err := c.send(req)
+		if err == nil {
+			return body, nil
+		}
+
+		// Short-lived credentials may have been revoked or expired early, refresh them once.
+		if !reauthenticated && res != nil && res.StatusCode == http.StatusUnauthorized && c.Auth.refreshable() {
+			reauthenticated = true
+			c.invalidateCredentials()
+			tflog.Debug(ctx, "nOps API rejected the credentials, refreshing them", map[string]any{"path": req.URL.Path})
+			continue
+		}
+
+		if !retryable || attempt >= c.MaxRetries || !shouldRetry(res, err) || ctx.Err() != nil {
+			return nil, err
+		}
+
+		wait := c.backoff(attempt, res)
+		// Don't wait for a retry that can't start before the operation deadline.
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < wait {
+			return nil, fmt.Errorf("%w (not retried, the operation deadline is reached before the next attempt in %s)", err, wait)
+		}
+		tflog.Warn(ctx, "Retrying nOps API request", map[string]any{
+			"method":  req.Method,
+			"path":    req.URL.Path,
+			"attempt": attempt + 1,
+			"wait":    wait.String(),
+			"error":   err.Error(),
+		})
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, errors.Join(err, ctx.Err())
+		case <-timer.C:
+		}
+		attempt++
+	}
+}
 
+// send performs a single attempt, returning the response body on a 2xx status.
+func (c *Client) send(req *http.Request) ([]byte, *http.Response, error) {
 	res, err := c.HTTPClient.Do(req)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	defer res.Body.Close()
 
 	body, err := io.ReadAll(res.Body)
 	if err != nil {
-		return nil, err
+		return nil, res, err
 	}
 
 	statusOK := res.StatusCode >= 200 && res.StatusCode < 300
 	if !statusOK {
-		return nil, fmt.Errorf("status: %d, body: %s", res.StatusCode, body)
+		return nil, res, newAPIError(res, body)
 	}
 
-	return body, err
+	return body, res, nil
 }
 
-func (c *Client) GetProjects() ([]Project, error) {
-	req, err := http.NewRequest("GET", fmt.Sprintf("%s/c/admin/projectaws/", c.HostURL), nil)
+// backoff returns how long to wait before the next attempt, preferring the
+// server provided Retry-After over the jittered exponential backoff.
+func (c *Client) backoff(attempt int, res *http.Response) time.Duration {
+	if res != nil {
+		if wait, ok := parseRetryAfter(res.Header.Get("Retry-After")); ok {
+			return min(wait, c.RetryWaitMax)
+		}
+	}
 
-	if err != nil {
-		return nil, err
+	wait := c.RetryWaitMax
+	if attempt < 32 {
+		wait = min(c.RetryWaitMin<<attempt, c.RetryWaitMax)
+	}
+	if wait <= 0 {
+		return 0
 	}
 
-	body, err := c.doRequest(req)
-	if err != nil {
-		return nil, err
+	// Full jitter on the upper half, so parallel resources don't retry in lockstep.
+	half := wait / 2
+	return half + rand.N(wait-half+1)
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete, http.MethodPatch:
+		return true
 	}
+	return false
+}
 
-	projects := []Project{}
-	err = json.Unmarshal(body, &projects)
-	if err != nil {
-		return nil, err
+func shouldRetry(res *http.Response, err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if res == nil {
+		// Transport level failure such as a reset connection.
+		return true
 	}
+	switch res.StatusCode {
+	case http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
 
-	return projects, nil
+// parseRetryAfter supports both the delay-seconds and HTTP-date forms of the header.
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return max(time.Until(date), 0), true
+	}
+	return 0, false
+}
+
+func (c *Client) fetchProjects(ctx context.Context) ([]Project, error) {
+	return listAll[Project](ctx, c, fmt.Sprintf("%s/c/admin/projectaws/", c.HostURL))
 }
 
-func (c *Client) CreateProject(project NewProject) (*Project, error) {
+func (c *Client) CreateProject(ctx context.Context, project NewProject) (*Project, error) {
+	defer c.invalidateProjects()
+
 	rb, err := json.Marshal(project)
 	if err != nil {
 		return nil, err
 	}
 
-	req, err := http.NewRequest("POST", fmt.Sprintf("%s/c/admin/projectaws/", c.HostURL), strings.NewReader(string(rb)))
+	req, err := http.NewRequestWithContext(ctx, "POST", fmt.Sprintf("%s/c/admin/projectaws/", c.HostURL), strings.NewReader(string(rb)))
 	if err != nil {
 		return nil, err
 	}
@@ -121,13 +300,15 @@ func (c *Client) CreateProject(project NewProject) (*Project, error) {
 	return &projects, nil
 }
 
-func (c *Client) UpdateProject(id int64, project UpdateProject) (*Project, error) {
+func (c *Client) UpdateProject(ctx context.Context, id int64, project UpdateProject) (*Project, error) {
+	defer c.invalidateProjects()
+
 	rb, err := json.Marshal(project)
 	if err != nil {
 		return nil, err
 	}
 
-	req, err := http.NewRequest("PATCH", fmt.Sprintf("%s/c/admin/projectaws/%d/", c.HostURL, id), strings.NewReader(string(rb)))
+	req, err := http.NewRequestWithContext(ctx, "PATCH", fmt.Sprintf("%s/c/admin/projectaws/%d/", c.HostURL, id), strings.NewReader(string(rb)))
 
 	if err != nil {
 		return nil, err
@@ -147,9 +328,10 @@ func (c *Client) UpdateProject(id int64, project UpdateProject) (*Project, error
 	return &projects, nil
 }
 
-func (c *Client) DeleteProject(id int64) error {
+func (c *Client) DeleteProject(ctx context.Context, id int64) error {
+	defer c.invalidateProjects()
 
-	req, err := http.NewRequest("DELETE", fmt.Sprintf("%s/c/admin/projectaws/%d/", c.HostURL, id), nil)
+	req, err := http.NewRequestWithContext(ctx, "DELETE", fmt.Sprintf("%s/c/admin/projectaws/%d/", c.HostURL, id), nil)
 	if err != nil {
 		return err
 	}
@@ -162,19 +344,23 @@ func (c *Client) DeleteProject(id int64) error {
 	return nil
 }
 
-func (c *Client) NotifyNops(payload Integration) (*IntegrationResponse, error) {
+func (c *Client) NotifyNops(ctx context.Context, payload Integration) (*IntegrationResponse, error) {
+	// The integration updates the role and bucket of the account's project.
+	defer c.invalidateProjects()
+
 	rb, err := json.Marshal(payload)
 	if err != nil {
 		return nil, err
 	}
 
-	req, err := http.NewRequest("POST", fmt.Sprintf("%s/c/aws/integration/", c.HostURL), strings.NewReader(string(rb)))
-	req.Header.Set("X-Aws-Account-Number", payload.AccountNumber)
+	req, err := http.NewRequestWithContext(ctx, "POST", fmt.Sprintf("%s/c/aws/integration/", c.HostURL), strings.NewReader(string(rb)))
 	if err != nil {
 		return nil, err
 	}
+	req.Header.Set("X-Aws-Account-Number", payload.AccountNumber)
 
-	body, err := c.doRequest(req)
+	// The integration notification is an upsert keyed on the account number, so replaying it is safe.
+	body, err := c.doRetrySafeRequest(req)
 	if err != nil {
 		return nil, err
 	}
@@ -188,19 +374,20 @@ func (c *Client) NotifyNops(payload Integration) (*IntegrationResponse, error) {
 	return &status, nil
 }
 
-func (c *Client) NotifyComputeCopilotOnboarding(payload ComputeCopilotOnboarding) error {
+func (c *Client) NotifyComputeCopilotOnboarding(ctx context.Context, payload ComputeCopilotOnboarding) error {
 	rb, err := json.Marshal(payload)
 	if err != nil {
 		return err
 	}
 
-	req, err := http.NewRequest("POST", fmt.Sprintf("%s/svc/karpenter_manager/agents/terraform/onboarding-confirmation", c.HostURL), strings.NewReader(string(rb)))
+	req, err := http.NewRequestWithContext(ctx, "POST", fmt.Sprintf("%s/svc/karpenter_manager/agents/terraform/onboarding-confirmation", c.HostURL), strings.NewReader(string(rb)))
 
 	if err != nil {
 		return err
 	}
 
-	_, err = c.doRequest(req)
+	// Onboarding confirmations overwrite the cluster list for the region, so replaying them is safe.
+	_, err = c.doRetrySafeRequest(req)
 	if err != nil {
 		return err
 	}
@@ -208,9 +395,9 @@ func (c *Client) NotifyComputeCopilotOnboarding(payload ComputeCopilotOnboarding
 	return nil
 }
 
-func (c *Client) GetComputeCopilotOnboarding(regionName string, accountId string) (*ComputeCopilotOnboarding, error) {
+func (c *Client) GetComputeCopilotOnboarding(ctx context.Context, regionName string, accountId string) (*ComputeCopilotOnboarding, error) {
 
-	req, err := http.NewRequest("GET", fmt.Sprintf("%s/svc/karpenter_manager/agents/terraform/onboarding-confirmation", c.HostURL), nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf("%s/svc/karpenter_manager/agents/terraform/onboarding-confirmation", c.HostURL), nil)
 
 	if err != nil {
 		return nil, err
@@ -235,9 +422,9 @@ func (c *Client) GetComputeCopilotOnboarding(regionName string, accountId string
 	return &result, nil
 }
 
-func (c *Client) DeleteComputeCopilotOnboarding(regionName string, accountId string) error {
+func (c *Client) DeleteComputeCopilotOnboarding(ctx context.Context, regionName string, accountId string) error {
 
-	req, err := http.NewRequest("DELETE", fmt.Sprintf("%s/svc/karpenter_manager/agents/terraform/onboarding", c.HostURL), nil)
+	req, err := http.NewRequestWithContext(ctx, "DELETE", fmt.Sprintf("%s/svc/karpenter_manager/agents/terraform/onboarding", c.HostURL), nil)
 	if err != nil {
 		return err
 	}
@@ -255,18 +442,19 @@ func (c *Client) DeleteComputeCopilotOnboarding(regionName string, accountId str
 	return nil
 }
 
-func (c *Client) NotifyContainerCostBucketSetup(payload ContainerCostBucketSetup) error {
+func (c *Client) NotifyContainerCostBucketSetup(ctx context.Context, payload ContainerCostBucketSetup) error {
 	rb, err := json.Marshal(payload)
 	if err != nil {
 		return err
 	}
 
-	req, err := http.NewRequest("POST", fmt.Sprintf("%s/c/admin/container_cost_bucket/setup/", c.HostURL), strings.NewReader(string(rb)))
+	req, err := http.NewRequestWithContext(ctx, "POST", fmt.Sprintf("%s/c/admin/container_cost_bucket/setup/", c.HostURL), strings.NewReader(string(rb)))
 	if err != nil {
 		return err
 	}
 
-	_, err = c.doRequest(req)
+	// Setup is keyed on the project and is already re-sent on every update, so replaying it is safe.
+	_, err = c.doRetrySafeRequest(req)
 	if err != nil {
 		return err
 	}
@@ -274,9 +462,18 @@ func (c *Client) NotifyContainerCostBucketSetup(payload ContainerCostBucketSetup
 	return nil
 }
 
-func (c *Client) GetContainerCostBucketSetupStatus() (*[]ContainerCostBucket, error) {
+func (c *Client) GetContainerCostBucketSetupStatus(ctx context.Context) (*[]ContainerCostBucket, error) {
+	result, err := listAll[ContainerCostBucket](ctx, c, fmt.Sprintf("%s/c/admin/container_cost_bucket/", c.HostURL))
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+func (c *Client) GetTargetedContainerCostBucketSetupStatus(ctx context.Context, id int64) (*ContainerCostBucket, error) {
 
-	req, err := http.NewRequest("GET", fmt.Sprintf("%s/c/admin/container_cost_bucket/", c.HostURL), nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf("%s/c/admin/container_cost_bucket/%d", c.HostURL, id), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -286,7 +483,7 @@ func (c *Client) GetContainerCostBucketSetupStatus() (*[]ContainerCostBucket, er
 		return nil, err
 	}
 
-	result := []ContainerCostBucket{}
+	result := ContainerCostBucket{}
 	err = json.Unmarshal(body, &result)
 	if err != nil {
 		return nil, err
@@ -295,9 +492,24 @@ func (c *Client) GetContainerCostBucketSetupStatus() (*[]ContainerCostBucket, er
 	return &result, nil
 }
 
-func (c *Client) GetTargetedContainerCostBucketSetupStatus(id int64) (*ContainerCostBucket, error) {
+func (c *Client) DeleteContainerCostBucket(ctx context.Context, id int64) error {
+
+	req, err := http.NewRequestWithContext(ctx, "DELETE", fmt.Sprintf("%s/c/admin/container_cost_bucket/%d/", c.HostURL, id), nil)
+	if err != nil {
+		return err
+	}
 
-	req, err := http.NewRequest("GET", fmt.Sprintf("%s/c/admin/container_cost_bucket/%d", c.HostURL, id), nil)
+	_, err = c.doRequest(req)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (c *Client) GetCallerIdentity(ctx context.Context) (*CallerIdentity, error) {
+
+	req, err := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf("%s/c/admin/whoami/", c.HostURL), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -307,7 +519,7 @@ func (c *Client) GetTargetedContainerCostBucketSetupStatus(id int64) (*Container
 		return nil, err
 	}
 
-	result := ContainerCostBucket{}
+	result := CallerIdentity{}
 	err = json.Unmarshal(body, &result)
 	if err != nil {
 		return nil, err
@@ -316,17 +528,23 @@ func (c *Client) GetTargetedContainerCostBucketSetupStatus(id int64) (*Container
 	return &result, nil
 }
 
-func (c *Client) DeleteContainerCostBucket(id int64) error {
+func (c *Client) GetIAMPolicyCatalog(ctx context.Context) (*IAMPolicyCatalog, error) {
 
-	req, err := http.NewRequest("DELETE", fmt.Sprintf("%s/c/admin/container_cost_bucket/%d/", c.HostURL, id), nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf("%s/c/admin/iam_policies/", c.HostURL), nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	_, err = c.doRequest(req)
+	body, err := c.doRequest(req)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	return nil
+	result := IAMPolicyCatalog{}
+	err = json.Unmarshal(body, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
 }
@@ -0,0 +1,229 @@
+package nops
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"terraform-provider-nops/nops/internal/fakenops"
+)
+
+func newTestClient(t *testing.T, handler http.HandlerFunc) *Client {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	host := server.URL
+	apiKey := "test-key"
+	client, err := NewClient(&host, &apiKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.RetryWaitMin = time.Millisecond
+	client.RetryWaitMax = 10 * time.Millisecond
+
+	return client
+}
+
+func TestClientRetriesIdempotentRequests(t *testing.T) {
+	var calls atomic.Int32
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(`[{"id": 1, "account_number": "123456789012"}]`))
+	})
+
+	projects, err := client.GetProjects(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(projects) != 1 || projects[0].ID != 1 {
+		t.Fatalf("unexpected projects: %+v", projects)
+	}
+	if got := calls.Load(); got != 3 {
+		t.Fatalf("expected 3 attempts, got %d", got)
+	}
+}
+
+func TestClientGivesUpAfterMaxRetries(t *testing.T) {
+	var calls atomic.Int32
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusBadGateway)
+	})
+	client.MaxRetries = 2
+
+	if _, err := client.GetProjects(context.Background()); err == nil {
+		t.Fatal("expected an error")
+	}
+	if got := calls.Load(); got != 3 {
+		t.Fatalf("expected 3 attempts, got %d", got)
+	}
+}
+
+func TestClientDoesNotRetryCreateProject(t *testing.T) {
+	var calls atomic.Int32
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+
+	if _, err := client.CreateProject(context.Background(), NewProject{Name: "test"}); err == nil {
+		t.Fatal("expected an error")
+	}
+	if got := calls.Load(); got != 1 {
+		t.Fatalf("expected a single attempt, got %d", got)
+	}
+}
+
+func TestClientRetriesRetrySafePostWithBody(t *testing.T) {
+	var calls atomic.Int32
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.ContentLength == 0 {
+			t.Errorf("attempt %d was sent without a body", calls.Load()+1)
+		}
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	})
+
+	if err := client.NotifyContainerCostBucketSetup(context.Background(), ContainerCostBucketSetup{Project: 1}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if got := calls.Load(); got != 2 {
+		t.Fatalf("expected 2 attempts, got %d", got)
+	}
+}
+
+func TestClientStopsRetryingWhenContextIsCancelled(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "60")
+		w.WriteHeader(http.StatusTooManyRequests)
+	})
+	client.RetryWaitMax = time.Minute
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, client.HostURL+"/c/admin/projectaws/", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+	if _, err := client.doRequest(req); err == nil {
+		t.Fatal("expected an error")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("retry did not stop on context cancellation, took %s", elapsed)
+	}
+}
+
+func TestClientDoesNotRetryPastTheDeadline(t *testing.T) {
+	var calls atomic.Int32
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Header().Set("Retry-After", "30")
+		w.WriteHeader(http.StatusTooManyRequests)
+	})
+	client.RetryWaitMax = time.Minute
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	start := time.Now()
+	if _, err := client.GetCallerIdentity(ctx); err == nil {
+		t.Fatal("expected an error")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("expected to give up without waiting, took %s", elapsed)
+	}
+	if got := calls.Load(); got != 1 {
+		t.Fatalf("expected a single attempt, got %d", got)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	if wait, ok := parseRetryAfter("3"); !ok || wait != 3*time.Second {
+		t.Fatalf("unexpected delay-seconds result: %s %t", wait, ok)
+	}
+	date := time.Now().Add(time.Hour).UTC().Format(http.TimeFormat)
+	if wait, ok := parseRetryAfter(date); !ok || wait <= 0 {
+		t.Fatalf("unexpected HTTP-date result: %s %t", wait, ok)
+	}
+	if _, ok := parseRetryAfter("soon"); ok {
+		t.Fatal("expected an invalid value to be ignored")
+	}
+}
+
+func TestClientAgainstFakeAPI(t *testing.T) {
+	server := fakenops.NewServer()
+	defer server.Close()
+	server.PageSize = 2
+	for _, account := range []string{"111111111111", "222222222222", "333333333333"} {
+		server.AddDiscoveredProject(account, "discovered-"+account)
+	}
+
+	host, apiKey := server.URL, server.APIKey
+	client, err := NewClient(&host, &apiKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.RetryWaitMin = time.Millisecond
+	client.RetryWaitMax = 10 * time.Millisecond
+	ctx := context.Background()
+
+	server.InjectFault(fakenops.Fault{Method: "GET", PathPrefix: "/c/admin/projectaws/", Status: http.StatusInternalServerError, Count: 1})
+	projects, err := client.GetProjects(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(projects) != 3 || projects[0].RoleName != "na" {
+		t.Fatalf("expected the 3 discovered projects across pages, got %+v", projects)
+	}
+
+	if err := client.NotifyContainerCostBucketSetup(ctx, ContainerCostBucketSetup{Project: int64(projects[0].ID)}); err != nil {
+		t.Fatal(err)
+	}
+	buckets, err := client.GetContainerCostBucketSetupStatus(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(*buckets) != 1 || (*buckets)[0].Status != fakenops.BucketStatusPending {
+		t.Fatalf("expected a pending bucket, got %+v", *buckets)
+	}
+	bucket, err := client.GetTargetedContainerCostBucketSetupStatus(ctx, (*buckets)[0].ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bucket.Status != fakenops.BucketStatusActive {
+		t.Fatalf("expected the bucket to become active, got %q", bucket.Status)
+	}
+
+	identity, err := client.GetCallerIdentity(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if identity.ClientID != server.ClientID || identity.Name != server.KeyName || len(identity.Scopes) != len(server.Scopes) {
+		t.Fatalf("unexpected caller identity: %+v", identity)
+	}
+
+	if _, err := client.CreateProject(ctx, NewProject{Name: "duplicate", AccountNumber: "111111111111"}); !IsConflict(err) {
+		t.Fatalf("expected a conflict for an account that already has a project, got %v", err)
+	}
+
+	server.InjectFault(fakenops.Fault{PathPrefix: "/svc/karpenter_manager/", Delay: time.Second, Count: 1})
+	client.HTTPClient.Timeout = 50 * time.Millisecond
+	client.MaxRetries = 0
+	if _, err := client.GetComputeCopilotOnboarding(ctx, "us-east-1", "1"); err == nil {
+		t.Fatal("expected the slow response to time out")
+	}
+}
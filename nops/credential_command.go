@@ -0,0 +1,85 @@
+package nops
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// commandCredential - JSON document printed by the api_key_command program.
+type commandCredential struct {
+	ApiKey string `json:"api_key"`
+	// Expiration is an optional RFC 3339 timestamp, the key is kept until the
+	// API rejects it when omitted.
+	Expiration *time.Time `json:"expiration"`
+}
+
+// usesApiKeyCommand reports whether the API key is obtained from an external command.
+func (a AuthStruct) usesApiKeyCommand() bool {
+	return len(a.ApiKeyCommand) > 0
+}
+
+// commandApiKey returns the cached API key, running the api_key_command
+// again when it is missing or about to expire.
+func (c *Client) commandApiKey(ctx context.Context) (string, error) {
+	c.token.mu.Lock()
+	defer c.token.mu.Unlock()
+
+	if c.token.accessToken != "" && (c.token.expiry.IsZero() || time.Until(c.token.expiry) > tokenExpiryDelta) {
+		return c.token.accessToken, nil
+	}
+
+	credential, err := runApiKeyCommand(ctx, c.Auth.ApiKeyCommand)
+	if err != nil {
+		return "", err
+	}
+
+	c.token.accessToken = credential.ApiKey
+	c.token.expiry = time.Time{}
+	if credential.Expiration != nil {
+		c.token.expiry = *credential.Expiration
+	}
+	tflog.Debug(ctx, "Obtained nOps API key from api_key_command", map[string]any{"expiration": c.token.expiry})
+
+	return c.token.accessToken, nil
+}
+
+// runApiKeyCommand runs the command and decodes the credential from its
+// output. Its stdout is never included in errors as it carries the key.
+func runApiKeyCommand(ctx context.Context, argv []string) (*commandCredential, error) {
+	if len(argv) == 0 || argv[0] == "" {
+		return nil, errors.New("api_key_command must name a program to run")
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, argv[0], argv[1:]...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("running %s: %w: %s", argv[0], err, msg)
+		}
+		return nil, fmt.Errorf("running %s: %w", argv[0], err)
+	}
+
+	credential := commandCredential{}
+	if err := json.Unmarshal(stdout.Bytes(), &credential); err != nil {
+		return nil, fmt.Errorf("%s did not print a valid JSON credential document: %w", argv[0], err)
+	}
+	if credential.ApiKey == "" {
+		return nil, fmt.Errorf("the credential document printed by %s did not include an api_key", argv[0])
+	}
+	if credential.Expiration != nil && !credential.Expiration.After(time.Now()) {
+		return nil, fmt.Errorf("the API key printed by %s expired at %s", argv[0], credential.Expiration.Format(time.RFC3339))
+	}
+
+	return &credential, nil
+}
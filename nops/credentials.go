@@ -0,0 +1,103 @@
+package nops
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DefaultProfile - profile read from the shared credentials file when none is selected.
+const DefaultProfile string = "default"
+
+// CredentialsProfile - named set of nOps credentials from the shared credentials file.
+type CredentialsProfile struct {
+	ApiKey string
+	Host   string
+}
+
+// errProfileNotFound is returned when the requested profile is missing from the credentials file.
+var errProfileNotFound = errors.New("profile not found")
+
+// SharedCredentialsFile - path of the shared credentials file, ~/.nops/credentials
+// unless overridden with the NOPS_SHARED_CREDENTIALS_FILE environment variable.
+func SharedCredentialsFile() (string, error) {
+	if file := os.Getenv("NOPS_SHARED_CREDENTIALS_FILE"); file != "" {
+		return file, nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("locating the home directory: %w", err)
+	}
+	return filepath.Join(home, ".nops", "credentials"), nil
+}
+
+// LoadCredentialsProfile - reads a named profile from an INI style credentials file:
+//
+//	[default]
+//	api_key = ...
+//	host    = https://app.nops.io/
+func LoadCredentialsProfile(file, name string) (*CredentialsProfile, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var (
+		profile *CredentialsProfile
+		section string
+		lineNo  int
+	)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") {
+			if !strings.HasSuffix(line, "]") {
+				return nil, fmt.Errorf("%s:%d: malformed section header %q", file, lineNo, line)
+			}
+			section = strings.TrimSpace(line[1 : len(line)-1])
+			if section == name && profile == nil {
+				profile = &CredentialsProfile{}
+			}
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("%s:%d: expected a key = value pair", file, lineNo)
+		}
+		if section != name {
+			continue
+		}
+
+		switch strings.TrimSpace(key) {
+		case "api_key":
+			profile.ApiKey = strings.TrimSpace(value)
+		case "host":
+			profile.Host = strings.TrimSpace(value)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading %s: %w", file, err)
+	}
+
+	if profile == nil {
+		return nil, fmt.Errorf("%w: %q in %s", errProfileNotFound, name, file)
+	}
+	return profile, nil
+}
+
+// isCredentialsFileMissing reports whether err is caused by a missing credentials file.
+func isCredentialsFileMissing(err error) bool {
+	return errors.Is(err, fs.ErrNotExist)
+}
@@ -0,0 +1,69 @@
+package nops
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+)
+
+const testCredentialsFile = `
+# nOps credentials
+[default]
+api_key = default-key
+
+[staging]
+api_key = staging-key
+host    = https://staging.nops.io/
+
+; section without an API key
+[host-only]
+host = https://eu.nops.io/
+`
+
+func TestLoadCredentialsProfile(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "credentials")
+	if err := os.WriteFile(file, []byte(testCredentialsFile), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, want := range map[string]CredentialsProfile{
+		"default":   {ApiKey: "default-key"},
+		"staging":   {ApiKey: "staging-key", Host: "https://staging.nops.io/"},
+		"host-only": {Host: "https://eu.nops.io/"},
+	} {
+		profile, err := LoadCredentialsProfile(file, name)
+		if err != nil {
+			t.Fatalf("%s: %s", name, err)
+		}
+		if *profile != want {
+			t.Errorf("%s: expected %+v, got %+v", name, want, *profile)
+		}
+	}
+
+	if _, err := LoadCredentialsProfile(file, "missing"); err == nil {
+		t.Fatal("expected an error for a missing profile")
+	}
+
+	malformed := filepath.Join(t.TempDir(), "malformed")
+	if err := os.WriteFile(malformed, []byte("[default]\napi_key\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadCredentialsProfile(malformed, "default"); err == nil {
+		t.Fatal("expected an error for a malformed file")
+	}
+}
+
+func TestLoadProfileOnlyFailsWhenSelected(t *testing.T) {
+	t.Setenv("NOPS_SHARED_CREDENTIALS_FILE", filepath.Join(t.TempDir(), "missing"))
+
+	var diags diag.Diagnostics
+	if profile := loadProfile("", &diags); profile != nil || diags.HasError() {
+		t.Fatalf("expected a missing file to be ignored without a selected profile, got %+v %v", profile, diags)
+	}
+
+	if loadProfile("staging", &diags); !diags.HasError() {
+		t.Fatal("expected an error for a selected profile without a credentials file")
+	}
+}
@@ -0,0 +1,92 @@
+package nops
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// DefaultDeployment - deployment used when neither a deployment nor a host is configured.
+const DefaultDeployment string = "us"
+
+// Deployment - nOps platform deployment the provider can talk to.
+type Deployment struct {
+	Name    string
+	HostURL string
+	// PrincipalAccountID is the AWS account nOps assumes integration roles
+	// from, empty when it isn't published for the deployment.
+	PrincipalAccountID string
+}
+
+// deployments lists the known nOps deployments by name, only add deployments
+// whose base URL is published by nOps as their hosts are trusted with API keys.
+var deployments = map[string]Deployment{
+	"us": {Name: "us", HostURL: HostURL, PrincipalAccountID: "202279780353"},
+}
+
+// LookupDeployment - returns the deployment with the given name.
+func LookupDeployment(name string) (Deployment, bool) {
+	d, ok := deployments[name]
+	return d, ok
+}
+
+// DeploymentNames - sorted names of the known deployments.
+func DeploymentNames() []string {
+	names := make([]string, 0, len(deployments))
+	for name := range deployments {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// isKnownHost reports whether host is the base URL of a known deployment.
+func isKnownHost(host string) bool {
+	for _, d := range deployments {
+		if d.HostURL == host {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeHost validates an nOps API base URL and returns it without a
+// trailing slash. It must be an https URL without a path, plain http is only
+// accepted for local stand-ins of the API.
+func normalizeHost(host string) (string, error) {
+	u, err := url.Parse(host)
+	if err != nil {
+		return "", err
+	}
+	if u.Host == "" {
+		return "", errors.New("expected an absolute URL such as " + HostURL)
+	}
+
+	switch u.Scheme {
+	case "https":
+	case "http":
+		if !isLoopback(u.Hostname()) {
+			return "", fmt.Errorf("plain http is only allowed for localhost, use https://%s", u.Host)
+		}
+	default:
+		return "", fmt.Errorf("unsupported scheme %q, expected https", u.Scheme)
+	}
+
+	if u.User != nil || (u.Path != "" && u.Path != "/") || u.RawQuery != "" || u.Fragment != "" {
+		return "", fmt.Errorf("expected a base URL without a path, such as %s://%s", u.Scheme, u.Host)
+	}
+
+	return strings.TrimSuffix(host, "/"), nil
+}
+
+// isLoopback reports whether hostname refers to the local machine.
+func isLoopback(hostname string) bool {
+	if hostname == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(hostname)
+	return ip != nil && ip.IsLoopback()
+}
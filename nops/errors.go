@@ -0,0 +1,154 @@
+package nops
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// NopsAPIError - error returned for any non 2xx response from the nOps API.
+type NopsAPIError struct {
+	// StatusCode is the HTTP status returned by the API.
+	StatusCode int
+	// Method and Endpoint identify the call that failed, the endpoint is the
+	// request path without the host or query string.
+	Method   string
+	Endpoint string
+	// RequestID is the server side request identifier, useful for support tickets.
+	RequestID string
+	// Payload is the parsed error document, empty when the body isn't JSON.
+	Payload APIErrorPayload
+	// Body is the raw response body.
+	Body []byte
+}
+
+// APIErrorPayload - error document returned by the nOps API. Validation
+// failures are returned as a map of field names to messages.
+type APIErrorPayload struct {
+	Detail string              `json:"detail"`
+	Code   string              `json:"code"`
+	Fields map[string][]string `json:"-"`
+}
+
+func (e *NopsAPIError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "nOps API %s %s returned status %d", e.Method, e.Endpoint, e.StatusCode)
+
+	if e.Payload.Code != "" {
+		fmt.Fprintf(&b, ", code: %s", e.Payload.Code)
+	}
+	if e.RequestID != "" {
+		fmt.Fprintf(&b, ", request ID: %s", e.RequestID)
+	}
+
+	switch {
+	case e.Payload.Detail != "":
+		fmt.Fprintf(&b, ": %s", e.Payload.Detail)
+	case len(e.Payload.Fields) > 0:
+		fields := make([]string, 0, len(e.Payload.Fields))
+		for field, messages := range e.Payload.Fields {
+			fields = append(fields, fmt.Sprintf("%s: %s", field, strings.Join(messages, " ")))
+		}
+		sort.Strings(fields)
+		fmt.Fprintf(&b, ": %s", strings.Join(fields, "; "))
+	case len(e.Body) > 0:
+		fmt.Fprintf(&b, ", body: %s", e.Body)
+	}
+
+	return b.String()
+}
+
+// newAPIError builds a NopsAPIError out of a failed response and its body.
+func newAPIError(res *http.Response, body []byte) *NopsAPIError {
+	apiErr := &NopsAPIError{
+		StatusCode: res.StatusCode,
+		Body:       body,
+		RequestID:  res.Header.Get("X-Request-Id"),
+	}
+	if apiErr.RequestID == "" {
+		apiErr.RequestID = res.Header.Get("X-Amzn-Requestid")
+	}
+	if res.Request != nil {
+		apiErr.Method = res.Request.Method
+		apiErr.Endpoint = res.Request.URL.Path
+	}
+
+	apiErr.Payload = parseAPIErrorPayload(body)
+
+	return apiErr
+}
+
+func parseAPIErrorPayload(body []byte) APIErrorPayload {
+	payload := APIErrorPayload{}
+
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return payload
+	}
+
+	for key, value := range raw {
+		var text string
+		var messages []string
+		switch {
+		case json.Unmarshal(value, &text) == nil:
+			switch key {
+			case "detail", "message", "error":
+				if payload.Detail == "" {
+					payload.Detail = text
+				}
+				continue
+			case "code":
+				payload.Code = text
+				continue
+			}
+			messages = []string{text}
+		case json.Unmarshal(value, &messages) == nil:
+		default:
+			continue
+		}
+
+		if payload.Fields == nil {
+			payload.Fields = map[string][]string{}
+		}
+		payload.Fields[key] = messages
+	}
+
+	return payload
+}
+
+// apiErrorStatus reports the HTTP status of err when it is a NopsAPIError.
+func apiErrorStatus(err error) (int, bool) {
+	var apiErr *NopsAPIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode, true
+	}
+	return 0, false
+}
+
+// IsNotFound reports whether err is a 404 returned by the nOps API.
+func IsNotFound(err error) bool {
+	status, ok := apiErrorStatus(err)
+	return ok && status == http.StatusNotFound
+}
+
+// IsConflict reports whether err is a 409 returned by the nOps API.
+func IsConflict(err error) bool {
+	status, ok := apiErrorStatus(err)
+	return ok && status == http.StatusConflict
+}
+
+// IsValidationError reports whether err is a 400 returned by the nOps API.
+func IsValidationError(err error) bool {
+	status, ok := apiErrorStatus(err)
+	return ok && status == http.StatusBadRequest
+}
+
+// IsUnauthorized reports whether err is a 401 or 403 returned by the nOps API,
+// usually caused by a missing, revoked or under-privileged API key.
+func IsUnauthorized(err error) bool {
+	status, ok := apiErrorStatus(err)
+	return ok && (status == http.StatusUnauthorized || status == http.StatusForbidden)
+}
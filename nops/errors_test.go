@@ -0,0 +1,58 @@
+package nops
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestClientReturnsStructuredAPIErrors(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Request-Id", "req-123")
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"detail": "Not found.", "code": "not_found"}`))
+	})
+
+	err := client.DeleteProject(context.Background(), 42)
+	if !IsNotFound(err) {
+		t.Fatalf("expected a not found error, got %v", err)
+	}
+	if IsConflict(err) || IsUnauthorized(err) || IsValidationError(err) {
+		t.Fatalf("error matched the wrong kind: %v", err)
+	}
+
+	wrapped := fmt.Errorf("deleting: %w", err)
+	if !IsNotFound(wrapped) {
+		t.Fatal("expected a wrapped error to be detected")
+	}
+
+	var apiErr *NopsAPIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("expected a NopsAPIError, got %T", err)
+	}
+	if apiErr.RequestID != "req-123" || apiErr.Method != http.MethodDelete || apiErr.Endpoint != "/c/admin/projectaws/42/" {
+		t.Fatalf("unexpected error fields: %+v", apiErr)
+	}
+	if apiErr.Payload.Code != "not_found" || apiErr.Payload.Detail != "Not found." {
+		t.Fatalf("unexpected payload: %+v", apiErr.Payload)
+	}
+}
+
+func TestAPIErrorMessageIncludesValidationFields(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"account_number": ["Ensure this field has no more than 12 characters."], "name": "This field is required."}`))
+	})
+
+	_, err := client.CreateProject(context.Background(), NewProject{})
+	if !IsValidationError(err) {
+		t.Fatalf("expected a validation error, got %v", err)
+	}
+	want := "account_number: Ensure this field has no more than 12 characters.; name: This field is required."
+	if !strings.Contains(err.Error(), want) {
+		t.Fatalf("expected %q in %q", want, err.Error())
+	}
+}
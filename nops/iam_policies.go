@@ -0,0 +1,87 @@
+package nops
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// IAM policy features, each granted by its own policy document.
+const (
+	IAMPolicyIntegration    string = "integration"
+	IAMPolicyEssentials     string = "essentials"
+	IAMPolicyComputeCopilot string = "compute_copilot"
+	IAMPolicyWAFR           string = "wafr"
+	IAMPolicySystemBucket   string = "system_bucket"
+)
+
+// bucketNamePlaceholder is replaced with the system bucket name in policy resources.
+const bucketNamePlaceholder = "{bucket_name}"
+
+// embeddedIAMPolicies is the policy catalog shipped with the provider, used
+// when the platform catalog can't be retrieved. Bump its version whenever the
+// permissions change.
+//
+//go:embed iam_policies.json
+var embeddedIAMPolicies []byte
+
+// EmbeddedIAMPolicyCatalog - policy catalog shipped with this provider version.
+func EmbeddedIAMPolicyCatalog() (*IAMPolicyCatalog, error) {
+	catalog := IAMPolicyCatalog{}
+	if err := json.Unmarshal(embeddedIAMPolicies, &catalog); err != nil {
+		return nil, fmt.Errorf("decoding the embedded IAM policies: %w", err)
+	}
+	return &catalog, catalog.Validate()
+}
+
+// Validate checks every feature has a policy granting at least one action.
+func (c IAMPolicyCatalog) Validate() error {
+	for _, feature := range []string{IAMPolicyIntegration, IAMPolicyEssentials, IAMPolicyComputeCopilot, IAMPolicyWAFR, IAMPolicySystemBucket} {
+		if len(c.Features[feature].Actions) == 0 {
+			return fmt.Errorf("the IAM policy catalog %q has no actions for %s", c.Version, feature)
+		}
+	}
+	return nil
+}
+
+// PolicyDocument - IAM policy JSON granting the actions of a feature. Keys are
+// sorted the same way as Terraform's jsonencode, so both produce the same document.
+func (c IAMPolicyCatalog) PolicyDocument(feature, bucketName string) (string, error) {
+	policy, ok := c.Features[feature]
+	if !ok {
+		return "", fmt.Errorf("the IAM policy catalog %q has no %s policy", c.Version, feature)
+	}
+
+	var resource any = "*"
+	if len(policy.Resources) > 0 {
+		resources := make([]string, 0, len(policy.Resources))
+		for _, r := range policy.Resources {
+			if strings.Contains(r, bucketNamePlaceholder) {
+				if bucketName == "" {
+					return "", fmt.Errorf("the %s policy requires a bucket name", feature)
+				}
+				r = strings.ReplaceAll(r, bucketNamePlaceholder, bucketName)
+			}
+			resources = append(resources, r)
+		}
+		resource = resources
+	}
+
+	document := map[string]any{
+		"Version": "2012-10-17",
+		"Statement": []any{
+			map[string]any{
+				"Effect":   "Allow",
+				"Action":   policy.Actions,
+				"Resource": resource,
+			},
+		},
+	}
+
+	raw, err := json.Marshal(document)
+	if err != nil {
+		return "", err
+	}
+	return string(raw), nil
+}
@@ -0,0 +1,46 @@
+package nops
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEmbeddedIAMPolicyCatalog(t *testing.T) {
+	catalog, err := EmbeddedIAMPolicyCatalog()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if catalog.Version == "" {
+		t.Fatal("the embedded catalog must be versioned")
+	}
+
+	// Same document as jsonencode of the essentials policy in the onboarding examples.
+	essentials, err := catalog.PolicyDocument(IAMPolicyEssentials, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"Statement":[{"Action":["cloudwatch:ListMetrics","events:CreateEventBus"],"Effect":"Allow","Resource":"*"}],"Version":"2012-10-17"}`
+	if essentials != want {
+		t.Fatalf("unexpected essentials policy:\n%s\nwant:\n%s", essentials, want)
+	}
+
+	if _, err := catalog.PolicyDocument(IAMPolicySystemBucket, ""); err == nil {
+		t.Fatal("expected the system bucket policy to require a bucket name")
+	}
+	bucket, err := catalog.PolicyDocument(IAMPolicySystemBucket, "nops-1-2-471112641702")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(bucket, `"Resource":["arn:aws:s3:::nops-1-2-471112641702","arn:aws:s3:::nops-1-2-471112641702/*"]`) {
+		t.Fatalf("expected the bucket to be substituted in the resources: %s", bucket)
+	}
+}
+
+func TestIAMPolicyCatalogValidate(t *testing.T) {
+	catalog := IAMPolicyCatalog{Version: "partial", Features: map[string]IAMPolicyFeature{
+		IAMPolicyIntegration: {Actions: []string{"ce:GetCostAndUsage"}},
+	}}
+	if err := catalog.Validate(); err == nil {
+		t.Fatal("expected a catalog missing features to be rejected")
+	}
+}
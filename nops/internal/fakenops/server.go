@@ -0,0 +1,702 @@
+// Package fakenops is a stateful, in-process stand-in for the nOps API used
+// by the provider tests. It models every endpoint the provider client calls
+// and can inject throttling, server errors and slow responses.
+package fakenops
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DefaultAPIKey - API key accepted by a server created without one.
+const DefaultAPIKey string = "fake-nops-api-key"
+
+// Project - project as returned by /c/admin/projectaws/.
+type Project struct {
+	ID                       int    `json:"id"`
+	Client                   int    `json:"client"`
+	Arn                      string `json:"arn"`
+	Bucket                   string `json:"bucket"`
+	AccountNumber            string `json:"account_number"`
+	Name                     string `json:"name"`
+	ExternalID               string `json:"external_id"`
+	RoleName                 string `json:"role_name"`
+	MasterPayerAccountNumber string `json:"master_payer_account_number,omitempty"`
+}
+
+// Onboarding - compute copilot onboarding of an account and region.
+type Onboarding struct {
+	ClusterArns []string `json:"cluster_arns"`
+	RegionName  string   `json:"region_name"`
+	Version     string   `json:"version"`
+	AccountID   string   `json:"account_id"`
+}
+
+// ContainerCostBucket - container cost bucket of a project.
+type ContainerCostBucket struct {
+	ID      int64  `json:"id"`
+	Project int64  `json:"project"`
+	Bucket  string `json:"bucket"`
+	Region  string `json:"region"`
+	Status  string `json:"status"`
+
+	reads int
+}
+
+// Bucket statuses.
+const (
+	BucketStatusPending string = "pending"
+	BucketStatusActive  string = "active"
+)
+
+// Fault - failure injected for the requests matching Method and PathPrefix,
+// an empty Method or PathPrefix matches every request.
+type Fault struct {
+	Method     string
+	PathPrefix string
+	// Status is returned instead of handling the request, zero only applies Delay.
+	Status int
+	// RetryAfter, when set, is sent as the Retry-After header.
+	RetryAfter string
+	// Delay is waited before answering, use it to trigger client timeouts.
+	Delay time.Duration
+	// Count is the number of requests the fault applies to, zero means forever.
+	Count int
+}
+
+// Server - fake nOps API backed by net/http/httptest.
+type Server struct {
+	*httptest.Server
+
+	// ClientID is the nOps client owning every project.
+	ClientID int
+	// ClientName, KeyName and Scopes are returned by /c/admin/whoami/.
+	ClientName string
+	KeyName    string
+	Scopes     []string
+	// APIKey is the accepted X-Nops-Api-Key value.
+	APIKey string
+	// OAuthClientID and OAuthClientSecret, when set, are exchanged at /o/token/ for bearer tokens.
+	OAuthClientID     string
+	OAuthClientSecret string
+	// IAMPolicies, when set, is served as the IAM policy catalog, which
+	// otherwise isn't found.
+	IAMPolicies json.RawMessage
+	// PageSize, when set, makes list endpoints answer with paginated envelopes.
+	PageSize int
+	// BucketActivationReads is the number of reads after which a pending
+	// container cost bucket becomes active.
+	BucketActivationReads int
+
+	mu          sync.Mutex
+	nextID      int
+	projects    map[int]*Project
+	onboardings map[string]*Onboarding
+	buckets     map[int64]*ContainerCostBucket
+	tokens      map[string]bool
+	faults      []*Fault
+	requests    []string
+}
+
+// NewServer - starts a fake nOps API, stop it with Close.
+func NewServer() *Server {
+	s := &Server{
+		ClientID:              15418,
+		ClientName:            "fake-client",
+		KeyName:               "terraform",
+		Scopes:                []string{"read", "write"},
+		APIKey:                DefaultAPIKey,
+		BucketActivationReads: 1,
+		nextID:                1000,
+		projects:              map[int]*Project{},
+		onboardings:           map[string]*Onboarding{},
+		buckets:               map[int64]*ContainerCostBucket{},
+		tokens:                map[string]bool{},
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// AddDiscoveredProject adds a project the platform discovered on its own,
+// which isn't integrated yet and has "na" as its role name and bucket.
+func (s *Server) AddDiscoveredProject(accountNumber, name string) Project {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return *s.newProject(name, accountNumber, "")
+}
+
+// AddIntegratedProject adds a project that already finished its integration.
+func (s *Server) AddIntegratedProject(accountNumber, name, bucket string) Project {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	project := s.newProject(name, accountNumber, "")
+	project.RoleName = "NopsIntegrationRole"
+	project.Arn = fmt.Sprintf("arn:aws:iam::%s:role/%s", accountNumber, project.RoleName)
+	project.Bucket = bucket
+	return *project
+}
+
+// SetMasterPayer sets the master payer account number nOps reports for a
+// project, which the projects added above don't have.
+func (s *Server) SetMasterPayer(id int, accountNumber string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if project, ok := s.projects[id]; ok {
+		project.MasterPayerAccountNumber = accountNumber
+	}
+}
+
+// Projects returns the projects currently stored, sorted by ID.
+func (s *Server) Projects() []Project {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.sortedProjects()
+}
+
+// Project returns the project with the given ID.
+func (s *Server) Project(id int) (Project, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	project, ok := s.projects[id]
+	if !ok {
+		return Project{}, false
+	}
+	return *project, true
+}
+
+// DeleteProject removes a project as if it was deleted outside of Terraform.
+func (s *Server) DeleteProject(id int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.projects, id)
+}
+
+// Onboarding returns the compute copilot onboarding of an account and region.
+func (s *Server) Onboarding(accountID, regionName string) (Onboarding, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	onboarding, ok := s.onboardings[onboardingKey(accountID, regionName)]
+	if !ok {
+		return Onboarding{}, false
+	}
+	return *onboarding, true
+}
+
+// DeleteOnboarding removes an onboarding as if the account was offboarded outside of Terraform.
+func (s *Server) DeleteOnboarding(accountID, regionName string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.onboardings, onboardingKey(accountID, regionName))
+}
+
+// ContainerCostBuckets returns the container cost buckets currently stored.
+func (s *Server) ContainerCostBuckets() []ContainerCostBucket {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.sortedBuckets()
+}
+
+// InjectFault makes the matching requests fail, faults apply in the order they were added.
+func (s *Server) InjectFault(fault Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f := fault
+	s.faults = append(s.faults, &f)
+}
+
+// Requests returns the "METHOD /path" of every request received, including failed ones.
+func (s *Server) Requests() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]string(nil), s.requests...)
+}
+
+// RequestCount returns how many requests were received for a method and path.
+func (s *Server) RequestCount(method, path string) int {
+	count := 0
+	for _, request := range s.Requests() {
+		if request == method+" "+path {
+			count++
+		}
+	}
+	return count
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.requests = append(s.requests, r.Method+" "+r.URL.Path)
+	fault := s.matchFault(r)
+	s.mu.Unlock()
+
+	if fault != nil {
+		if fault.Delay > 0 {
+			select {
+			case <-time.After(fault.Delay):
+			case <-r.Context().Done():
+				return
+			}
+		}
+		if fault.Status != 0 {
+			if fault.RetryAfter != "" {
+				w.Header().Set("Retry-After", fault.RetryAfter)
+			}
+			writeJSON(w, fault.Status, map[string]string{"detail": http.StatusText(fault.Status), "code": "injected_fault"})
+			return
+		}
+	}
+
+	w.Header().Set("X-Request-Id", uuid.NewString())
+
+	if r.URL.Path == "/o/token/" {
+		s.handleToken(w, r)
+		return
+	}
+
+	if !s.authenticated(r) {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"detail": "Authentication credentials were not provided.", "code": "not_authenticated"})
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	path := r.URL.Path
+	switch {
+	case path == "/c/admin/whoami/":
+		s.handleWhoami(w, r)
+	case path == "/c/admin/iam_policies/" && s.IAMPolicies != nil:
+		writeJSON(w, http.StatusOK, s.IAMPolicies)
+	case path == "/c/admin/projectaws/":
+		s.handleProjects(w, r)
+	case strings.HasPrefix(path, "/c/admin/projectaws/"):
+		s.handleProject(w, r, strings.Trim(strings.TrimPrefix(path, "/c/admin/projectaws/"), "/"))
+	case path == "/c/aws/integration/":
+		s.handleIntegration(w, r)
+	case path == "/svc/karpenter_manager/agents/terraform/onboarding-confirmation":
+		s.handleOnboardingConfirmation(w, r)
+	case path == "/svc/karpenter_manager/agents/terraform/onboarding":
+		s.handleOnboardingDelete(w, r)
+	case path == "/c/admin/container_cost_bucket/setup/":
+		s.handleBucketSetup(w, r)
+	case path == "/c/admin/container_cost_bucket/":
+		s.handleBuckets(w, r)
+	case strings.HasPrefix(path, "/c/admin/container_cost_bucket/"):
+		s.handleBucket(w, r, strings.Trim(strings.TrimPrefix(path, "/c/admin/container_cost_bucket/"), "/"))
+	default:
+		writeNotFound(w)
+	}
+}
+
+// matchFault returns the first active fault matching the request, consuming one of its uses.
+func (s *Server) matchFault(r *http.Request) *Fault {
+	for i, fault := range s.faults {
+		if fault.Method != "" && fault.Method != r.Method {
+			continue
+		}
+		if !strings.HasPrefix(r.URL.Path, fault.PathPrefix) {
+			continue
+		}
+		if fault.Count > 0 {
+			fault.Count--
+			if fault.Count == 0 {
+				s.faults = append(s.faults[:i], s.faults[i+1:]...)
+			}
+		}
+		return fault
+	}
+	return nil
+}
+
+func (s *Server) authenticated(r *http.Request) bool {
+	if key := r.Header.Get("X-Nops-Api-Key"); key != "" {
+		return key == s.APIKey
+	}
+
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		return false
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.tokens[token]
+}
+
+func (s *Server) handleToken(w http.ResponseWriter, r *http.Request) {
+	id, secret, ok := r.BasicAuth()
+	if r.Method != http.MethodPost || r.FormValue("grant_type") != "client_credentials" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "unsupported_grant_type"})
+		return
+	}
+	if !ok || s.OAuthClientID == "" || id != s.OAuthClientID || secret != s.OAuthClientSecret {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_client"})
+		return
+	}
+
+	token := uuid.NewString()
+	s.mu.Lock()
+	s.tokens[token] = true
+	s.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, map[string]any{"access_token": token, "token_type": "Bearer", "expires_in": 3600})
+}
+
+func (s *Server) handleWhoami(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeMethodNotAllowed(w)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"client":      s.ClientID,
+		"client_name": s.ClientName,
+		"name":        s.KeyName,
+		"scopes":      s.Scopes,
+	})
+}
+
+func (s *Server) handleProjects(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		s.writeList(w, r, s.sortedProjects())
+	case http.MethodPost:
+		var body struct {
+			Name                     string `json:"name"`
+			AccountNumber            string `json:"account_number"`
+			MasterPayerAccountNumber string `json:"master_payer_account_number"`
+		}
+		if !decode(w, r, &body) {
+			return
+		}
+
+		fields := map[string][]string{}
+		if body.Name == "" {
+			fields["name"] = []string{"This field may not be blank."}
+		}
+		if body.AccountNumber == "" {
+			fields["account_number"] = []string{"This field may not be blank."}
+		}
+		if len(fields) > 0 {
+			writeJSON(w, http.StatusBadRequest, fields)
+			return
+		}
+		for _, project := range s.projects {
+			if project.AccountNumber == body.AccountNumber {
+				writeJSON(w, http.StatusConflict, map[string][]string{"account_number": {"project aws with this account number already exists."}})
+				return
+			}
+		}
+
+		writeJSON(w, http.StatusCreated, s.newProject(body.Name, body.AccountNumber, body.MasterPayerAccountNumber))
+	default:
+		writeMethodNotAllowed(w)
+	}
+}
+
+func (s *Server) handleProject(w http.ResponseWriter, r *http.Request, rawID string) {
+	id, err := strconv.Atoi(rawID)
+	project, ok := s.projects[id]
+	if err != nil || !ok {
+		writeNotFound(w)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, http.StatusOK, project)
+	case http.MethodPatch:
+		var body struct {
+			Name          *string `json:"name"`
+			AccountNumber *string `json:"account_number"`
+		}
+		if !decode(w, r, &body) {
+			return
+		}
+		if body.Name != nil {
+			project.Name = *body.Name
+		}
+		if body.AccountNumber != nil {
+			project.AccountNumber = *body.AccountNumber
+		}
+		writeJSON(w, http.StatusOK, project)
+	case http.MethodDelete:
+		delete(s.projects, id)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeMethodNotAllowed(w)
+	}
+}
+
+func (s *Server) handleIntegration(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeMethodNotAllowed(w)
+		return
+	}
+
+	var body struct {
+		RoleArn       string `json:"role_arn"`
+		BucketName    string `json:"bucket_name"`
+		AccountNumber string `json:"account_number"`
+		ExternalID    string `json:"external_id"`
+		RequestType   string `json:"RequestType"`
+	}
+	if !decode(w, r, &body) {
+		return
+	}
+
+	if header := r.Header.Get("X-Aws-Account-Number"); header != body.AccountNumber {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"detail": "X-Aws-Account-Number does not match account_number."})
+		return
+	}
+
+	var project *Project
+	for _, candidate := range s.projects {
+		if candidate.AccountNumber == body.AccountNumber {
+			project = candidate
+		}
+	}
+	if project == nil {
+		writeJSON(w, http.StatusNotFound, map[string]string{"detail": "No project found for this account."})
+		return
+	}
+	if project.ExternalID != body.ExternalID {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"detail": "External ID does not match the project."})
+		return
+	}
+
+	project.Arn = body.RoleArn
+	project.Bucket = body.BucketName
+	project.RoleName = body.RoleArn[strings.LastIndex(body.RoleArn, "/")+1:]
+
+	writeJSON(w, http.StatusOK, map[string]string{"status": "SUCCESS"})
+}
+
+func (s *Server) handleOnboardingConfirmation(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		onboarding, ok := s.onboardings[onboardingKey(r.URL.Query().Get("account_id"), r.URL.Query().Get("region_name"))]
+		if !ok {
+			writeNotFound(w)
+			return
+		}
+		writeJSON(w, http.StatusOK, onboarding)
+	case http.MethodPost:
+		var body Onboarding
+		if !decode(w, r, &body) {
+			return
+		}
+		if body.RegionName == "" || body.AccountID == "" {
+			writeJSON(w, http.StatusBadRequest, map[string][]string{"region_name": {"This field is required."}, "account_id": {"This field is required."}})
+			return
+		}
+		// The API doesn't keep the order the clusters were sent in.
+		sort.Sort(sort.Reverse(sort.StringSlice(body.ClusterArns)))
+		s.onboardings[onboardingKey(body.AccountID, body.RegionName)] = &body
+		writeJSON(w, http.StatusCreated, body)
+	default:
+		writeMethodNotAllowed(w)
+	}
+}
+
+func (s *Server) handleOnboardingDelete(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		writeMethodNotAllowed(w)
+		return
+	}
+
+	key := onboardingKey(r.URL.Query().Get("account_id"), r.URL.Query().Get("region_name"))
+	if _, ok := s.onboardings[key]; !ok {
+		writeNotFound(w)
+		return
+	}
+	delete(s.onboardings, key)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleBucketSetup(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeMethodNotAllowed(w)
+		return
+	}
+
+	var body struct {
+		Project int64 `json:"project"`
+	}
+	if !decode(w, r, &body) {
+		return
+	}
+
+	project, ok := s.projects[int(body.Project)]
+	if !ok {
+		writeJSON(w, http.StatusBadRequest, map[string][]string{"project": {fmt.Sprintf("Invalid pk \"%d\" - object does not exist.", body.Project)}})
+		return
+	}
+
+	for _, bucket := range s.buckets {
+		if bucket.Project == body.Project {
+			writeJSON(w, http.StatusOK, bucket)
+			return
+		}
+	}
+
+	s.nextID++
+	bucket := &ContainerCostBucket{
+		ID:      int64(s.nextID),
+		Project: body.Project,
+		Bucket:  "nops-container-cost-" + project.AccountNumber,
+		Region:  "us-east-1",
+		Status:  BucketStatusPending,
+	}
+	s.buckets[bucket.ID] = bucket
+	writeJSON(w, http.StatusCreated, bucket)
+}
+
+func (s *Server) handleBuckets(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeMethodNotAllowed(w)
+		return
+	}
+
+	for _, bucket := range s.buckets {
+		s.readBucket(bucket)
+	}
+	s.writeList(w, r, s.sortedBuckets())
+}
+
+func (s *Server) handleBucket(w http.ResponseWriter, r *http.Request, rawID string) {
+	id, err := strconv.ParseInt(rawID, 10, 64)
+	bucket, ok := s.buckets[id]
+	if err != nil || !ok {
+		writeNotFound(w)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		s.readBucket(bucket)
+		writeJSON(w, http.StatusOK, bucket)
+	case http.MethodDelete:
+		delete(s.buckets, id)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeMethodNotAllowed(w)
+	}
+}
+
+// readBucket moves pending buckets to active once they were read enough times,
+// mimicking the platform validating the bucket in the background.
+func (s *Server) readBucket(bucket *ContainerCostBucket) {
+	bucket.reads++
+	if bucket.Status == BucketStatusPending && bucket.reads > s.BucketActivationReads {
+		bucket.Status = BucketStatusActive
+	}
+}
+
+// writeList answers with a bare array, or a paginated envelope when PageSize is set.
+func (s *Server) writeList(w http.ResponseWriter, r *http.Request, items any) {
+	if s.PageSize <= 0 {
+		writeJSON(w, http.StatusOK, items)
+		return
+	}
+
+	raw, _ := json.Marshal(items)
+	var all []json.RawMessage
+	_ = json.Unmarshal(raw, &all)
+
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	page = max(page, 1)
+	start := min((page-1)*s.PageSize, len(all))
+	end := min(start+s.PageSize, len(all))
+
+	var next *string
+	if end < len(all) {
+		link := fmt.Sprintf("%s%s?page=%d", s.URL, r.URL.Path, page+1)
+		next = &link
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"count":   len(all),
+		"next":    next,
+		"results": append([]json.RawMessage{}, all[start:end]...),
+	})
+}
+
+func (s *Server) newProject(name, accountNumber, masterPayerAccountNumber string) *Project {
+	s.nextID++
+	project := &Project{
+		ID:                       s.nextID,
+		Client:                   s.ClientID,
+		Arn:                      fmt.Sprintf("arn:aws:iam::%s:role/na", accountNumber),
+		Bucket:                   "na",
+		AccountNumber:            accountNumber,
+		Name:                     name,
+		ExternalID:               uuid.NewString(),
+		RoleName:                 "na",
+		MasterPayerAccountNumber: masterPayerAccountNumber,
+	}
+	s.projects[project.ID] = project
+	return project
+}
+
+func (s *Server) sortedProjects() []Project {
+	projects := make([]Project, 0, len(s.projects))
+	for _, project := range s.projects {
+		projects = append(projects, *project)
+	}
+	sort.Slice(projects, func(i, j int) bool { return projects[i].ID < projects[j].ID })
+	return projects
+}
+
+func (s *Server) sortedBuckets() []ContainerCostBucket {
+	buckets := make([]ContainerCostBucket, 0, len(s.buckets))
+	for _, bucket := range s.buckets {
+		buckets = append(buckets, *bucket)
+	}
+	sort.Slice(buckets, func(i, j int) bool { return buckets[i].ID < buckets[j].ID })
+	return buckets
+}
+
+func onboardingKey(accountID, regionName string) string {
+	return accountID + "/" + regionName
+}
+
+func decode(w http.ResponseWriter, r *http.Request, v any) bool {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"detail": "JSON parse error - " + err.Error()})
+		return false
+	}
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeNotFound(w http.ResponseWriter) {
+	writeJSON(w, http.StatusNotFound, map[string]string{"detail": "Not found.", "code": "not_found"})
+}
+
+func writeMethodNotAllowed(w http.ResponseWriter) {
+	writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"detail": "Method not allowed.", "code": "method_not_allowed"})
+}
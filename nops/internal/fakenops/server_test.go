@@ -0,0 +1,164 @@
+package fakenops
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestCreateProject(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+
+	var created Project
+	status := send(t, server, "POST", "/c/admin/projectaws/", `{"name": "test", "account_number": "123456789012", "master_payer_account_number": "123456789012"}`, &created)
+	if status != http.StatusCreated {
+		t.Fatalf("expected the project to be created, got %d", status)
+	}
+	if created.Client != server.ClientID || created.RoleName != "na" || created.ExternalID == "" {
+		t.Fatalf("unexpected project %+v", created)
+	}
+
+	// The provider tells an existing project apart from invalid input by the status.
+	var conflict map[string][]string
+	if status := send(t, server, "POST", "/c/admin/projectaws/", `{"name": "other", "account_number": "123456789012"}`, &conflict); status != http.StatusConflict {
+		t.Fatalf("expected a duplicate account number to conflict, got %d", status)
+	}
+	if len(conflict["account_number"]) != 1 {
+		t.Fatalf("expected the conflict to be reported on account_number, got %v", conflict)
+	}
+	if status := send(t, server, "POST", "/c/admin/projectaws/", `{"name": "", "account_number": "123456789012"}`, nil); status != http.StatusBadRequest {
+		t.Fatalf("expected a blank name to be rejected, got %d", status)
+	}
+	if projects := server.Projects(); len(projects) != 1 {
+		t.Fatalf("expected a single project, got %+v", projects)
+	}
+}
+
+func TestUpdateAndDeleteProject(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+	project := server.AddDiscoveredProject("123456789012", "discovered")
+	projectPath := fmt.Sprintf("/c/admin/projectaws/%d/", project.ID)
+
+	var updated Project
+	if status := send(t, server, "PATCH", projectPath, `{"name": "renamed"}`, &updated); status != http.StatusOK || updated.Name != "renamed" || updated.AccountNumber != "123456789012" {
+		t.Fatalf("expected only the name to be updated, got %d %+v", status, updated)
+	}
+	if status := send(t, server, "DELETE", projectPath, "", nil); status != http.StatusNoContent {
+		t.Fatalf("expected the project to be deleted, got %d", status)
+	}
+	if status := send(t, server, "GET", projectPath, "", nil); status != http.StatusNotFound {
+		t.Fatalf("expected the deleted project not to be found, got %d", status)
+	}
+	if count := server.RequestCount("PATCH", projectPath); count != 1 {
+		t.Fatalf("expected a single recorded PATCH request, got %d", count)
+	}
+}
+
+func TestAuthentication(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+
+	req, _ := http.NewRequest("GET", server.URL+"/c/admin/whoami/", nil)
+	req.Header.Set("X-Nops-Api-Key", "wrong")
+	res, err := server.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected an unknown API key to be rejected, got %d", res.StatusCode)
+	}
+}
+
+func TestInjectFault(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+	server.InjectFault(Fault{Method: "GET", PathPrefix: "/c/admin/projectaws/", Status: http.StatusTooManyRequests, RetryAfter: "1", Count: 2})
+
+	for i := 0; i < 2; i++ {
+		if status := send(t, server, "GET", "/c/admin/projectaws/", "", nil); status != http.StatusTooManyRequests {
+			t.Fatalf("expected request %d to be throttled, got %d", i, status)
+		}
+	}
+	if status := send(t, server, "GET", "/c/admin/projectaws/", "", nil); status != http.StatusOK {
+		t.Fatalf("expected the fault to be used up, got %d", status)
+	}
+	if status := send(t, server, "GET", "/c/admin/whoami/", "", nil); status != http.StatusOK {
+		t.Fatalf("expected other paths not to match the fault, got %d", status)
+	}
+}
+
+func TestPagination(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+	server.PageSize = 2
+	for i := 0; i < 3; i++ {
+		server.AddDiscoveredProject(fmt.Sprintf("12345678901%d", i), fmt.Sprintf("project-%d", i))
+	}
+
+	var page struct {
+		Count   int       `json:"count"`
+		Next    *string   `json:"next"`
+		Results []Project `json:"results"`
+	}
+	send(t, server, "GET", "/c/admin/projectaws/", "", &page)
+	if page.Count != 3 || len(page.Results) != 2 || page.Next == nil {
+		t.Fatalf("unexpected first page %+v", page)
+	}
+	send(t, server, "GET", strings.TrimPrefix(*page.Next, server.URL), "", &page)
+	if len(page.Results) != 1 || page.Next != nil || page.Results[0].Name != "project-2" {
+		t.Fatalf("unexpected last page %+v", page)
+	}
+}
+
+func TestContainerCostBucketActivation(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+	project := server.AddIntegratedProject("123456789012", "integrated", "bucket")
+
+	var bucket ContainerCostBucket
+	if status := send(t, server, "POST", "/c/admin/container_cost_bucket/setup/", fmt.Sprintf(`{"project": %d}`, project.ID), &bucket); status != http.StatusCreated {
+		t.Fatalf("expected the bucket to be set up, got %d", status)
+	}
+
+	bucketPath := fmt.Sprintf("/c/admin/container_cost_bucket/%d/", bucket.ID)
+	for _, expected := range []string{BucketStatusPending, BucketStatusActive} {
+		send(t, server, "GET", bucketPath, "", &bucket)
+		if bucket.Status != expected {
+			t.Fatalf("expected the bucket to be %s, got %s", expected, bucket.Status)
+		}
+	}
+}
+
+// send calls the server with its API key and decodes the response into out
+// when it's not nil, returning the status code.
+func send(t *testing.T, server *Server, method, path, body string, out any) int {
+	t.Helper()
+
+	req, err := http.NewRequest(method, server.URL+path, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("X-Nops-Api-Key", server.APIKey)
+	res, err := server.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+
+	raw, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out != nil {
+		if err := json.Unmarshal(raw, out); err != nil {
+			t.Fatalf("decoding %s: %s", raw, err)
+		}
+	}
+	return res.StatusCode
+}
@@ -0,0 +1,330 @@
+// Package vcr records the HTTP traffic of the provider to cassette files and
+// replays it, so acceptance tests can run without a live nOps tenant.
+package vcr
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// EnvMode - environment variable selecting the recorder mode.
+const EnvMode string = "NOPS_VCR_MODE"
+
+// Mode - behaviour of the recorder.
+type Mode string
+
+const (
+	// ModeDisabled sends every request to the real API without recording it.
+	ModeDisabled Mode = ""
+	// ModeRecord sends every request to the real API and saves the scrubbed interactions.
+	ModeRecord Mode = "record"
+	// ModeReplay answers every request from the cassette, without any network access.
+	ModeReplay Mode = "replay"
+)
+
+// redacted replaces the value of sensitive headers.
+const redacted string = "REDACTED"
+
+// externalIDPrefix prefixes the deterministic placeholders of scrubbed external IDs.
+const externalIDPrefix string = "scrubbed-external-id-"
+
+var (
+	sensitiveHeaders = []string{"X-Nops-Api-Key", "Authorization", "Cookie", "Set-Cookie"}
+	externalIDFields = map[string]bool{"external_id": true, "ExternalID": true}
+	// sensitiveFields are credentials in JSON bodies, such as the bearer
+	// tokens returned by the OAuth token endpoint.
+	sensitiveFields = map[string]bool{"access_token": true, "refresh_token": true, "id_token": true, "api_key": true}
+)
+
+// ModeFromEnv - reads the recorder mode from NOPS_VCR_MODE.
+func ModeFromEnv() (Mode, error) {
+	mode := Mode(strings.ToLower(strings.TrimSpace(os.Getenv(EnvMode))))
+	switch mode {
+	case ModeDisabled, ModeRecord, ModeReplay:
+		return mode, nil
+	}
+	return ModeDisabled, fmt.Errorf("unsupported %s value %q, expected record or replay", EnvMode, mode)
+}
+
+// Cassette - recorded interactions of a test.
+type Cassette struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Interaction - a scrubbed request and the response it received.
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+// Request - recorded request, the URL is stored without its host.
+type Request struct {
+	Method  string      `json:"method"`
+	URL     string      `json:"url"`
+	Headers http.Header `json:"headers,omitempty"`
+	Body    string      `json:"body,omitempty"`
+}
+
+// Response - recorded response.
+type Response struct {
+	StatusCode int         `json:"status_code"`
+	Headers    http.Header `json:"headers,omitempty"`
+	Body       string      `json:"body,omitempty"`
+}
+
+// Recorder - http.RoundTripper recording to, or replaying from, a cassette file.
+type Recorder struct {
+	mode  Mode
+	path  string
+	inner http.RoundTripper
+
+	mu          sync.Mutex
+	cassette    Cassette
+	used        []bool
+	externalIDs map[string]string
+}
+
+// New - creates a recorder for the cassette at path. In replay mode the
+// cassette must exist, inner is only used when recording or disabled.
+func New(mode Mode, path string, inner http.RoundTripper) (*Recorder, error) {
+	if inner == nil {
+		inner = http.DefaultTransport
+	}
+
+	r := &Recorder{
+		mode:        mode,
+		path:        path,
+		inner:       inner,
+		externalIDs: map[string]string{},
+	}
+
+	if mode == ModeReplay {
+		raw, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("reading cassette: %w", err)
+		}
+		if err := json.Unmarshal(raw, &r.cassette); err != nil {
+			return nil, fmt.Errorf("decoding cassette %s: %w", path, err)
+		}
+		r.used = make([]bool, len(r.cassette.Interactions))
+	}
+
+	return r, nil
+}
+
+// Mode returns the mode of the recorder.
+func (r *Recorder) Mode() Mode {
+	return r.mode
+}
+
+// Wrap returns a copy of the recorder sending live traffic through inner,
+// sharing the cassette so every provider instance of a test records to it.
+func (r *Recorder) Wrap(inner http.RoundTripper) http.RoundTripper {
+	if r.mode == ModeDisabled {
+		return inner
+	}
+	return &wrapped{recorder: r, inner: inner}
+}
+
+type wrapped struct {
+	recorder *Recorder
+	inner    http.RoundTripper
+}
+
+func (w *wrapped) RoundTrip(req *http.Request) (*http.Response, error) {
+	return w.recorder.roundTrip(req, w.inner)
+}
+
+// RoundTrip implements http.RoundTripper.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	return r.roundTrip(req, r.inner)
+}
+
+func (r *Recorder) roundTrip(req *http.Request, inner http.RoundTripper) (*http.Response, error) {
+	var reqBody []byte
+	if req.Body != nil {
+		var err error
+		reqBody, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		req.Body = io.NopCloser(bytes.NewReader(reqBody))
+	}
+
+	switch r.mode {
+	case ModeReplay:
+		return r.replay(req, reqBody)
+	case ModeRecord:
+		return r.record(req, reqBody, inner)
+	default:
+		return inner.RoundTrip(req)
+	}
+}
+
+func (r *Recorder) record(req *http.Request, reqBody []byte, inner http.RoundTripper) (*http.Response, error) {
+	res, err := inner.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	resBody, err := io.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	res.Body = io.NopCloser(bytes.NewReader(resBody))
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.cassette.Interactions = append(r.cassette.Interactions, Interaction{
+		Request: Request{
+			Method:  req.Method,
+			URL:     req.URL.RequestURI(),
+			Headers: scrubHeaders(req.Header),
+			Body:    r.scrubBody(reqBody),
+		},
+		Response: Response{
+			StatusCode: res.StatusCode,
+			Headers:    scrubHeaders(res.Header),
+			Body:       r.scrubBody(resBody),
+		},
+	})
+
+	return res, nil
+}
+
+// replay answers with the first unused interaction matching the request. Once
+// every match was used the last one is served again, since the number of
+// refreshes Terraform runs may vary between versions.
+func (r *Recorder) replay(req *http.Request, reqBody []byte) (*http.Response, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	body := r.scrubBody(reqBody)
+	match := -1
+	for i, interaction := range r.cassette.Interactions {
+		if interaction.Request.Method != req.Method || interaction.Request.URL != req.URL.RequestURI() || interaction.Request.Body != body {
+			continue
+		}
+		match = i
+		if !r.used[i] {
+			break
+		}
+	}
+	if match < 0 {
+		return nil, fmt.Errorf("no interaction recorded in %s for %s %s", r.path, req.Method, req.URL.RequestURI())
+	}
+	r.used[match] = true
+
+	recorded := r.cassette.Interactions[match].Response
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", recorded.StatusCode, http.StatusText(recorded.StatusCode)),
+		StatusCode:    recorded.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        recorded.Headers.Clone(),
+		Body:          io.NopCloser(strings.NewReader(recorded.Body)),
+		ContentLength: int64(len(recorded.Body)),
+		Request:       req,
+	}, nil
+}
+
+// Stop saves the cassette when recording, it is a no-op in the other modes.
+func (r *Recorder) Stop() error {
+	if r.mode != ModeRecord {
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	raw, err := json.MarshalIndent(r.cassette, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(r.path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(r.path, append(raw, '\n'), 0o600)
+}
+
+func scrubHeaders(headers http.Header) http.Header {
+	scrubbed := headers.Clone()
+	for _, name := range sensitiveHeaders {
+		if scrubbed.Get(name) != "" {
+			scrubbed.Set(name, redacted)
+		}
+	}
+	return scrubbed
+}
+
+// scrubBody redacts credentials and replaces external IDs in JSON bodies with
+// placeholders, the same ID always mapping to the same placeholder within a cassette.
+func (r *Recorder) scrubBody(body []byte) string {
+	if len(body) == 0 {
+		return ""
+	}
+
+	var doc any
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	if err := decoder.Decode(&doc); err != nil {
+		return string(body)
+	}
+
+	scrubbed, err := json.Marshal(r.scrubValue(doc))
+	if err != nil {
+		return string(body)
+	}
+	return string(scrubbed)
+}
+
+func (r *Recorder) scrubValue(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, field := range v {
+			if _, ok := field.(string); ok && sensitiveFields[key] {
+				v[key] = redacted
+				continue
+			}
+			if id, ok := field.(string); ok && externalIDFields[key] {
+				v[key] = r.placeholder(id)
+				continue
+			}
+			v[key] = r.scrubValue(field)
+		}
+	case []any:
+		for i, item := range v {
+			v[i] = r.scrubValue(item)
+		}
+	}
+	return value
+}
+
+func (r *Recorder) placeholder(id string) string {
+	if id == "" || strings.HasPrefix(id, externalIDPrefix) {
+		return id
+	}
+	if placeholder, ok := r.externalIDs[id]; ok {
+		return placeholder
+	}
+	placeholder := fmt.Sprintf("%s%d", externalIDPrefix, len(r.externalIDs)+1)
+	r.externalIDs[id] = placeholder
+	return placeholder
+}
+
+// IsCassetteNotFound reports whether err was caused by a missing cassette.
+func IsCassetteNotFound(err error) bool {
+	return errors.Is(err, os.ErrNotExist)
+}
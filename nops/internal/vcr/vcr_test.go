@@ -0,0 +1,139 @@
+package vcr
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestRecordThenReplay(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[{"id": 1, "external_id": "real-external-id"}]`))
+	}))
+	defer server.Close()
+
+	cassette := filepath.Join(t.TempDir(), "cassettes", "test.json")
+
+	recorder, err := New(ModeRecord, cassette, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	recorded := get(t, &http.Client{Transport: recorder.Wrap(http.DefaultTransport)}, server.URL+"/c/admin/projectaws/")
+	if !strings.Contains(recorded, "real-external-id") {
+		t.Fatalf("recording must not alter the live response: %s", recorded)
+	}
+	if err := recorder.Stop(); err != nil {
+		t.Fatal(err)
+	}
+
+	raw, err := os.ReadFile(cassette)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, secret := range []string{"secret-key", "real-external-id"} {
+		if strings.Contains(string(raw), secret) {
+			t.Fatalf("cassette leaks %q:\n%s", secret, raw)
+		}
+	}
+
+	replayer, err := New(ModeReplay, cassette, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The host doesn't matter on replay, nothing is sent over the network.
+	replayed := get(t, &http.Client{Transport: replayer}, "http://127.0.0.1:1/c/admin/projectaws/")
+	if !strings.Contains(replayed, externalIDPrefix+"1") {
+		t.Fatalf("expected the scrubbed external ID to be replayed: %s", replayed)
+	}
+	if calls.Load() != 1 {
+		t.Fatalf("expected the server to be called only while recording, got %d calls", calls.Load())
+	}
+
+	req, _ := http.NewRequest("GET", "http://127.0.0.1:1/c/admin/container_cost_bucket/", nil)
+	if _, err := replayer.RoundTrip(req); err == nil {
+		t.Fatal("expected an error for a request missing from the cassette")
+	}
+}
+
+func TestRecordScrubsOAuthTokens(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"access_token": "secret-access-token", "refresh_token": "secret-refresh-token", "token_type": "Bearer", "expires_in": 3600}`))
+	}))
+	defer server.Close()
+
+	cassette := filepath.Join(t.TempDir(), "token.json")
+	recorder, err := New(ModeRecord, cassette, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req, err := http.NewRequest("POST", server.URL+"/o/token/", strings.NewReader("grant_type=client_credentials"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth("client-id", "secret-client-secret")
+	res, err := (&http.Client{Transport: recorder.Wrap(http.DefaultTransport)}).Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(res.Body)
+	res.Body.Close()
+	if !strings.Contains(string(body), "secret-access-token") {
+		t.Fatalf("recording must not alter the live response: %s", body)
+	}
+	if err := recorder.Stop(); err != nil {
+		t.Fatal(err)
+	}
+
+	raw, err := os.ReadFile(cassette)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, secret := range []string{"secret-access-token", "secret-refresh-token", "Y2xpZW50LWlkOnNlY3JldC1jbGllbnQtc2VjcmV0"} {
+		if strings.Contains(string(raw), secret) {
+			t.Fatalf("cassette leaks %q:\n%s", secret, raw)
+		}
+	}
+	if !strings.Contains(string(raw), "Bearer") {
+		t.Fatalf("expected the non-sensitive token fields to be kept:\n%s", raw)
+	}
+}
+
+func TestReplayRequiresCassette(t *testing.T) {
+	_, err := New(ModeReplay, filepath.Join(t.TempDir(), "missing.json"), nil)
+	if !IsCassetteNotFound(err) {
+		t.Fatalf("expected a missing cassette error, got %v", err)
+	}
+}
+
+func get(t *testing.T, client *http.Client, url string) string {
+	t.Helper()
+
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("X-Nops-Api-Key", "secret-key")
+
+	res, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(body)
+}
@@ -9,6 +9,8 @@ type Project struct {
 	Name          string `json:"name"`
 	ExternalID    string `json:"external_id"`
 	RoleName      string `json:"role_name"`
+	// MasterPayerAccountNumber may be empty, such as for projects discovered by the platform.
+	MasterPayerAccountNumber string `json:"master_payer_account_number"`
 }
 
 type NewProject struct {
@@ -60,3 +62,20 @@ type ContainerCostBucket struct {
 	Region  string `json:"region"`
 	Status  string `json:"status"`
 }
+
+type CallerIdentity struct {
+	ClientID   int      `json:"client"`
+	ClientName string   `json:"client_name"`
+	Name       string   `json:"name"`
+	Scopes     []string `json:"scopes"`
+}
+
+type IAMPolicyCatalog struct {
+	Version  string                      `json:"version"`
+	Features map[string]IAMPolicyFeature `json:"features"`
+}
+
+type IAMPolicyFeature struct {
+	Actions   []string `json:"actions"`
+	Resources []string `json:"resources"`
+}
@@ -0,0 +1,61 @@
+package nops
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// NotApplicable - sentinel used by the platform for unset names, such as the
+// bucket and role of a project that isn't integrated yet.
+const NotApplicable string = "na"
+
+// maxProjectNameLength - longest nOps project name accepted by the platform.
+const maxProjectNameLength = 255
+
+var (
+	awsAccountIDPattern = regexp.MustCompile(`^[0-9]{12}$`)
+	bucketNamePattern   = regexp.MustCompile(`^[a-z0-9][a-z0-9.-]{1,61}[a-z0-9]$`)
+	// iamRoleARNPattern captures the account ID of an IAM role ARN in any AWS partition.
+	iamRoleARNPattern = regexp.MustCompile(`^arn:aws(?:-cn|-us-gov)?:iam::([0-9]{12}):role/(?:[\w+=,.@-]+/)*[\w+=,.@-]{1,64}$`)
+	// projectNamePattern rejects control characters and surrounding whitespace.
+	projectNamePattern = regexp.MustCompile(`^[^\s\p{Cc}](?:[^\p{Cc}]*[^\s\p{Cc}])?$`)
+)
+
+// isAWSAccountID reports whether value is a 12 digit AWS account ID.
+func isAWSAccountID(value string) bool {
+	return awsAccountIDPattern.MatchString(value)
+}
+
+// roleARNAccountID returns the AWS account ID of an IAM role ARN.
+func roleARNAccountID(arn string) (string, bool) {
+	match := iamRoleARNPattern.FindStringSubmatch(arn)
+	if match == nil {
+		return "", false
+	}
+	return match[1], true
+}
+
+// validateBucketName checks value against the S3 bucket naming rules.
+func validateBucketName(value string) error {
+	if !bucketNamePattern.MatchString(value) {
+		return fmt.Errorf("%q is not a valid S3 bucket name, it must be 3 to 63 lowercase letters, digits, dots or hyphens, starting and ending with a letter or digit", value)
+	}
+	return nil
+}
+
+// SystemBucketName - name of the S3 bucket holding the nOps system data of an
+// account, following the platform convention nops-<project id>-<client id>-<account id>.
+func SystemBucketName(projectID, clientID int64, accountID string) (string, error) {
+	if projectID <= 0 || clientID <= 0 {
+		return "", fmt.Errorf("the project and client IDs must be positive, got %d and %d", projectID, clientID)
+	}
+	if !isAWSAccountID(accountID) {
+		return "", fmt.Errorf("%q is not a 12 digit AWS account ID", accountID)
+	}
+
+	name := fmt.Sprintf("nops-%d-%d-%s", projectID, clientID, accountID)
+	if err := validateBucketName(name); err != nil {
+		return "", err
+	}
+	return name, nil
+}
@@ -0,0 +1,40 @@
+package nops
+
+import "testing"
+
+func TestSystemBucketName(t *testing.T) {
+	name, err := SystemBucketName(1234, 15418, "471112641702")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name != "nops-1234-15418-471112641702" {
+		t.Fatalf("unexpected bucket name %s", name)
+	}
+
+	for _, tc := range []struct {
+		projectID, clientID int64
+		accountID           string
+	}{
+		{0, 15418, "471112641702"},
+		{1234, -1, "471112641702"},
+		{1234, 15418, "na"},
+		{1234, 15418, "47111264170"},
+	} {
+		if _, err := SystemBucketName(tc.projectID, tc.clientID, tc.accountID); err == nil {
+			t.Errorf("expected an error for %+v", tc)
+		}
+	}
+}
+
+func TestValidateBucketName(t *testing.T) {
+	for _, name := range []string{"nops-1-2-471112641702", "my.bucket", "abc"} {
+		if err := validateBucketName(name); err != nil {
+			t.Errorf("%s: %s", name, err)
+		}
+	}
+	for _, name := range []string{"ab", "-bucket", "bucket-", "Bucket", "my_bucket", string(make([]byte, 64))} {
+		if err := validateBucketName(name); err == nil {
+			t.Errorf("%q: expected an error", name)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package nops
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ datasource.DataSource              = &callerIdentityDataSource{}
+	_ datasource.DataSourceWithConfigure = &callerIdentityDataSource{}
+)
+
+func NewCallerIdentityDataSource() datasource.DataSource {
+	return &callerIdentityDataSource{}
+}
+
+// Data source implementation.
+type callerIdentityDataSource struct {
+	client *Client
+}
+
+type callerIdentityDataSourceModel struct {
+	ClientID   types.Int64  `tfsdk:"client_id"`
+	ClientName types.String `tfsdk:"client_name"`
+	Name       types.String `tfsdk:"name"`
+	Scopes     types.List   `tfsdk:"scopes"`
+}
+
+// Metadata returns the data source type name.
+func (d *callerIdentityDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_caller_identity"
+}
+
+// Schema defines the schema for the data source.
+func (d *callerIdentityDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "The caller identity datasource returns the nOps client and API key the provider is authenticated as. Use it to assert a configuration runs against the expected nOps client.",
+		Attributes: map[string]schema.Attribute{
+			"client_id": schema.Int64Attribute{
+				Computed:    true,
+				Description: "nOps client identifier",
+			},
+			"client_name": schema.StringAttribute{
+				Computed:    true,
+				Description: "nOps client name",
+			},
+			"name": schema.StringAttribute{
+				Computed:    true,
+				Description: "Name of the API key or user the provider is authenticated as",
+			},
+			"scopes": schema.ListAttribute{
+				Computed:    true,
+				ElementType: types.StringType,
+				Description: "Scopes allowed for the credentials",
+			},
+		},
+	}
+}
+
+// Read refreshes the Terraform state with the latest data.
+func (d *callerIdentityDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	// Reuse the identity resolved when the provider validated its credentials.
+	identity := d.client.Identity
+	if identity == nil {
+		var err error
+		identity, err = d.client.GetCallerIdentity(ctx)
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Error getting nOps caller identity",
+				err.Error(),
+			)
+			return
+		}
+	}
+
+	scopes, diags := types.ListValueFrom(ctx, types.StringType, identity.Scopes)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	state := callerIdentityDataSourceModel{
+		ClientID:   types.Int64Value(int64(identity.ClientID)),
+		ClientName: types.StringValue(identity.ClientName),
+		Name:       types.StringValue(identity.Name),
+		Scopes:     scopes,
+	}
+
+	// Set state
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Configure adds the provider configured client to the data source.
+func (d *callerIdentityDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	// Add a nil check when handling ProviderData because Terraform
+	// sets that data after it calls the ConfigureProvider RPC.
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*Client)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *Client, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	d.client = client
+}
@@ -0,0 +1,52 @@
+package nops
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestCallerIdentityDataSourceFakeAPI(t *testing.T) {
+	server := newTestAccFakeServer(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: fakeProviderConfig(server) + `
+data "nops_caller_identity" "test" {}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.nops_caller_identity.test", "client_id", "15418"),
+					resource.TestCheckResourceAttr("data.nops_caller_identity.test", "client_name", "fake-client"),
+					resource.TestCheckResourceAttr("data.nops_caller_identity.test", "name", "terraform"),
+					resource.TestCheckResourceAttr("data.nops_caller_identity.test", "scopes.#", "2"),
+				),
+			},
+		},
+	})
+}
+
+func TestProviderValidatesCredentialsFakeAPI(t *testing.T) {
+	server := newTestAccFakeServer(t)
+	server.APIKey = "another-key"
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+provider "nops" {
+  nops_host            = "` + server.URL + `"
+  nops_api_key         = "wrong-key"
+  validate_credentials = true
+}
+
+data "nops_caller_identity" "test" {}
+`,
+				ExpectError: regexp.MustCompile("Invalid nOps Credentials"),
+			},
+		},
+	})
+}
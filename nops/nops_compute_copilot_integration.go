@@ -7,6 +7,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/types"
@@ -25,11 +26,12 @@ type computeCopilotIntegrationResource struct {
 }
 
 type computeCopilotIntegrationModel struct {
-	LastUpdated types.String `tfsdk:"last_updated"`
-	ClusterArns types.List   `tfsdk:"cluster_arns"`
-	RegionName  types.String `tfsdk:"region_name"`
-	Version     types.String `tfsdk:"version"`
-	AccountID   types.String `tfsdk:"account_id"`
+	LastUpdated types.String   `tfsdk:"last_updated"`
+	ClusterArns types.List     `tfsdk:"cluster_arns"`
+	RegionName  types.String   `tfsdk:"region_name"`
+	Version     types.String   `tfsdk:"version"`
+	AccountID   types.String   `tfsdk:"account_id"`
+	Timeouts    timeouts.Value `tfsdk:"timeouts"`
 }
 
 // computeCopilotResource is a helper function to simplify the provider implementation.
@@ -65,7 +67,7 @@ func (r *computeCopilotIntegrationResource) Metadata(_ context.Context, req reso
 }
 
 // Schema defines the schema for the resource.
-func (r *computeCopilotIntegrationResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+func (r *computeCopilotIntegrationResource) Schema(ctx context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
 	resp.Schema = schema.Schema{
 		Description: "Notifies the nOps platform a new cluster has been onboarded to nOps with the required input values." +
 			" This resource is mostly used only for secure connection with nOps APIs.",
@@ -92,6 +94,14 @@ func (r *computeCopilotIntegrationResource) Schema(_ context.Context, _ resource
 				Description: "nOps account ID associated with the AWS account where the clusters are hosted.",
 			},
 		},
+		Blocks: map[string]schema.Block{
+			"timeouts": timeouts.Block(ctx, timeouts.Opts{
+				Create: true,
+				Read:   true,
+				Update: true,
+				Delete: true,
+			}),
+		},
 	}
 }
 
@@ -104,6 +114,12 @@ func (r *computeCopilotIntegrationResource) Create(ctx context.Context, req reso
 		return
 	}
 
+	ctx, cancel := withTimeout(ctx, plan.Timeouts.Create, defaultCreateTimeout, &resp.Diagnostics)
+	defer cancel()
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	cluster_arns := make([]string, 0, len(plan.ClusterArns.Elements()))
 	diags = plan.ClusterArns.ElementsAs(ctx, &cluster_arns, false)
 	if diags.HasError() {
@@ -116,7 +132,7 @@ func (r *computeCopilotIntegrationResource) Create(ctx context.Context, req reso
 	integration.RegionName = plan.RegionName.ValueString()
 	integration.Version = plan.Version.ValueString()
 	integration.AccountID = plan.AccountID.ValueString()
-	err := r.client.NotifyComputeCopilotOnboarding(integration)
+	err := r.client.NotifyComputeCopilotOnboarding(ctx, integration)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error notifying nOps",
@@ -145,7 +161,13 @@ func (r *computeCopilotIntegrationResource) Read(ctx context.Context, req resour
 		return
 	}
 
-	onboarding, err := r.client.GetComputeCopilotOnboarding(state.RegionName.ValueString(), state.AccountID.ValueString())
+	ctx, cancel := withTimeout(ctx, state.Timeouts.Read, defaultReadTimeout, &resp.Diagnostics)
+	defer cancel()
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	onboarding, err := r.client.GetComputeCopilotOnboarding(ctx, state.RegionName.ValueString(), state.AccountID.ValueString())
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error getting remote compute copilot onboarding data",
@@ -196,6 +218,12 @@ func (r *computeCopilotIntegrationResource) Update(ctx context.Context, req reso
 		return
 	}
 
+	ctx, cancel := withTimeout(ctx, plan.Timeouts.Update, defaultUpdateTimeout, &resp.Diagnostics)
+	defer cancel()
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	clusterArns := make([]string, 0, len(plan.ClusterArns.Elements()))
 	diags = plan.ClusterArns.ElementsAs(ctx, &clusterArns, false)
 	if diags.HasError() {
@@ -208,7 +236,7 @@ func (r *computeCopilotIntegrationResource) Update(ctx context.Context, req reso
 	integration.RegionName = plan.RegionName.ValueString()
 	integration.Version = plan.Version.ValueString()
 	integration.AccountID = plan.AccountID.ValueString()
-	err := r.client.NotifyComputeCopilotOnboarding(integration)
+	err := r.client.NotifyComputeCopilotOnboarding(ctx, integration)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error notifying nOps",
@@ -236,7 +264,17 @@ func (r *computeCopilotIntegrationResource) Delete(ctx context.Context, req reso
 		return
 	}
 
-	err := r.client.DeleteComputeCopilotOnboarding(state.RegionName.ValueString(), state.AccountID.ValueString())
+	ctx, cancel := withTimeout(ctx, state.Timeouts.Delete, defaultDeleteTimeout, &resp.Diagnostics)
+	defer cancel()
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	err := r.client.DeleteComputeCopilotOnboarding(ctx, state.RegionName.ValueString(), state.AccountID.ValueString())
+	if IsNotFound(err) {
+		tflog.Warn(ctx, "Compute copilot onboarding was already deleted in nOps for region "+state.RegionName.ValueString())
+		return
+	}
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error deleting project",
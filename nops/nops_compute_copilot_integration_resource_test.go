@@ -1,15 +1,17 @@
 package nops
 
 import (
+	"fmt"
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
 	"github.com/hashicorp/terraform-plugin-testing/plancheck"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
 )
 
 func TestComputeCopilotIntegrationResource(t *testing.T) {
 	resource.Test(t, resource.TestCase{
-		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactoriesWithVCR(t),
 		Steps: []resource.TestStep{
 			// Create and Read testing
 			{
@@ -64,3 +66,34 @@ resource "nops_compute_copilot_integration" "test" {
 		},
 	})
 }
+
+func TestComputeCopilotIntegrationResourceFakeAPI(t *testing.T) {
+	server := newTestAccFakeServer(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		CheckDestroy: func(_ *terraform.State) error {
+			if _, ok := server.Onboarding("23986", "us-west-2"); ok {
+				return fmt.Errorf("expected the onboarding to be deleted")
+			}
+			return nil
+		},
+		Steps: []resource.TestStep{
+			// Create and Read testing, the API returns the clusters in another order
+			{
+				Config: fakeProviderConfig(server) + `
+resource "nops_compute_copilot_integration" "test" {
+  cluster_arns = ["arn:aws:eks:us-west-2:123456789012:cluster/a", "arn:aws:eks:us-west-2:123456789012:cluster/b"]
+  region_name  = "us-west-2"
+  version      = "1.0.0"
+  account_id   = 23986
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("nops_compute_copilot_integration.test", "cluster_arns.0", "arn:aws:eks:us-west-2:123456789012:cluster/a"),
+					resource.TestCheckResourceAttr("nops_compute_copilot_integration.test", "version", "1.0.0"),
+				),
+			},
+		},
+	})
+}
@@ -6,6 +6,7 @@ import (
 	"strconv"
 	"time"
 
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
@@ -25,12 +26,13 @@ type containerCostBucketResource struct {
 }
 
 type containerCostBucketModel struct {
-	ID          types.Int64  `tfsdk:"id"`
-	LastUpdated types.String `tfsdk:"last_updated"`
-	ProjectId   types.Int64  `tfsdk:"project_id"`
-	Status      types.String `tfsdk:"status"`
-	Region      types.String `tfsdk:"region"`
-	Bucket      types.String `tfsdk:"bucket"`
+	ID          types.Int64    `tfsdk:"id"`
+	LastUpdated types.String   `tfsdk:"last_updated"`
+	ProjectId   types.Int64    `tfsdk:"project_id"`
+	Status      types.String   `tfsdk:"status"`
+	Region      types.String   `tfsdk:"region"`
+	Bucket      types.String   `tfsdk:"bucket"`
+	Timeouts    timeouts.Value `tfsdk:"timeouts"`
 }
 
 // computeCopilotResource is a helper function to simplify the provider implementation.
@@ -66,7 +68,7 @@ func (r *containerCostBucketResource) Metadata(_ context.Context, req resource.M
 }
 
 // Schema defines the schema for the resource.
-func (r *containerCostBucketResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+func (r *containerCostBucketResource) Schema(ctx context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
 	resp.Schema = schema.Schema{
 		Description: "Notifies the nOps platform a new container cost bucket was created for the backend to fetch metadata from it." +
 			" This resource is mostly used only for secure connection with nOps APIs.",
@@ -96,6 +98,14 @@ func (r *containerCostBucketResource) Schema(_ context.Context, _ resource.Schem
 				Description: "nOps Container Cost Bucket integration status.",
 			},
 		},
+		Blocks: map[string]schema.Block{
+			"timeouts": timeouts.Block(ctx, timeouts.Opts{
+				Create: true,
+				Read:   true,
+				Update: true,
+				Delete: true,
+			}),
+		},
 	}
 }
 
@@ -108,10 +118,16 @@ func (r *containerCostBucketResource) Create(ctx context.Context, req resource.C
 		return
 	}
 
+	ctx, cancel := withTimeout(ctx, plan.Timeouts.Create, defaultCreateTimeout, &resp.Diagnostics)
+	defer cancel()
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	var containerCostBucket ContainerCostBucketSetup
 	containerCostBucket.Project = plan.ProjectId.ValueInt64()
 
-	err := r.client.NotifyContainerCostBucketSetup(containerCostBucket)
+	err := r.client.NotifyContainerCostBucketSetup(ctx, containerCostBucket)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error notifying nOps",
@@ -120,7 +136,7 @@ func (r *containerCostBucketResource) Create(ctx context.Context, req resource.C
 		return
 	}
 
-	allContainerBuckets, err := r.client.GetContainerCostBucketSetupStatus()
+	allContainerBuckets, err := r.client.GetContainerCostBucketSetupStatus(ctx)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error getting remote compute copilot onboarding data",
@@ -158,7 +174,13 @@ func (r *containerCostBucketResource) Read(ctx context.Context, req resource.Rea
 		return
 	}
 
-	containerCostBucketStatus, err := r.client.GetTargetedContainerCostBucketSetupStatus(state.ID.ValueInt64())
+	ctx, cancel := withTimeout(ctx, state.Timeouts.Read, defaultReadTimeout, &resp.Diagnostics)
+	defer cancel()
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	containerCostBucketStatus, err := r.client.GetTargetedContainerCostBucketSetupStatus(ctx, state.ID.ValueInt64())
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error getting remote compute copilot onboarding data",
@@ -191,10 +213,16 @@ func (r *containerCostBucketResource) Update(ctx context.Context, req resource.U
 		return
 	}
 
+	ctx, cancel := withTimeout(ctx, plan.Timeouts.Update, defaultUpdateTimeout, &resp.Diagnostics)
+	defer cancel()
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	var containerCostBucket ContainerCostBucketSetup
 	containerCostBucket.Project = plan.ProjectId.ValueInt64()
 
-	err := r.client.NotifyContainerCostBucketSetup(containerCostBucket)
+	err := r.client.NotifyContainerCostBucketSetup(ctx, containerCostBucket)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error notifying nOps",
@@ -203,7 +231,7 @@ func (r *containerCostBucketResource) Update(ctx context.Context, req resource.U
 		return
 	}
 
-	allContainerBuckets, err := r.client.GetContainerCostBucketSetupStatus()
+	allContainerBuckets, err := r.client.GetContainerCostBucketSetupStatus(ctx)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error getting remote compute copilot onboarding data",
@@ -242,7 +270,17 @@ func (r *containerCostBucketResource) Delete(ctx context.Context, req resource.D
 		return
 	}
 
-	err := r.client.DeleteContainerCostBucket(state.ID.ValueInt64())
+	ctx, cancel := withTimeout(ctx, state.Timeouts.Delete, defaultDeleteTimeout, &resp.Diagnostics)
+	defer cancel()
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	err := r.client.DeleteContainerCostBucket(ctx, state.ID.ValueInt64())
+	if IsNotFound(err) {
+		tflog.Warn(ctx, fmt.Sprintf("Container cost bucket %d was already deleted in nOps", state.ID.ValueInt64()))
+		return
+	}
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error deleting container cost bucket",
@@ -1,15 +1,20 @@
 package nops
 
 import (
+	"fmt"
+	"strconv"
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
 	"github.com/hashicorp/terraform-plugin-testing/plancheck"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+
+	"terraform-provider-nops/nops/internal/fakenops"
 )
 
 func TestContainerCostBucketResource(t *testing.T) {
 	resource.Test(t, resource.TestCase{
-		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactoriesWithVCR(t),
 		Steps: []resource.TestStep{
 			// Create and Read testing
 			{
@@ -51,3 +56,43 @@ resource "nops_container_cost_bucket" "test" {
 		},
 	})
 }
+
+func TestContainerCostBucketResourceFakeAPI(t *testing.T) {
+	server := newTestAccFakeServer(t)
+	project := server.AddIntegratedProject("123456789012", "payer", "nops-bucket")
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		CheckDestroy: func(_ *terraform.State) error {
+			if buckets := server.ContainerCostBuckets(); len(buckets) != 0 {
+				return fmt.Errorf("expected every container cost bucket to be deleted, found %+v", buckets)
+			}
+			return nil
+		},
+		Steps: []resource.TestStep{
+			// Create, with the setup call failing once
+			{
+				PreConfig: func() {
+					server.InjectFault(fakenops.Fault{Method: "POST", PathPrefix: "/c/admin/container_cost_bucket/setup/", Status: 503, Count: 1})
+				},
+				Config: fakeProviderConfig(server) + fmt.Sprintf(`
+resource "nops_container_cost_bucket" "test" {
+  project_id = %d
+}
+`, project.ID),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("nops_container_cost_bucket.test", "bucket", "nops-container-cost-123456789012"),
+					resource.TestCheckResourceAttr("nops_container_cost_bucket.test", "region", "us-east-1"),
+					resource.TestCheckResourceAttr("nops_container_cost_bucket.test", "project_id", strconv.Itoa(project.ID)),
+				),
+			},
+			// The bucket becomes active once the platform validated it
+			{
+				RefreshState: true,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("nops_container_cost_bucket.test", "status", fakenops.BucketStatusActive),
+				),
+			},
+		},
+	})
+}
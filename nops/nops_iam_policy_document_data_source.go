@@ -0,0 +1,235 @@
+package nops
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ datasource.DataSource              = &iamPolicyDocumentDataSource{}
+	_ datasource.DataSourceWithConfigure = &iamPolicyDocumentDataSource{}
+)
+
+// IAM policy catalog sources.
+const (
+	iamPolicySourcePlatform = "platform"
+	iamPolicySourceEmbedded = "embedded"
+)
+
+func NewIAMPolicyDocumentDataSource() datasource.DataSource {
+	return &iamPolicyDocumentDataSource{}
+}
+
+// Data source implementation.
+type iamPolicyDocumentDataSource struct {
+	client *Client
+}
+
+type iamPolicyDocumentDataSourceModel struct {
+	Essentials         types.Bool   `tfsdk:"essentials"`
+	ComputeCopilot     types.Bool   `tfsdk:"compute_copilot"`
+	WAFR               types.Bool   `tfsdk:"wafr"`
+	SystemBucket       types.Bool   `tfsdk:"system_bucket"`
+	BucketName         types.String `tfsdk:"bucket_name"`
+	Offline            types.Bool   `tfsdk:"offline"`
+	Version            types.String `tfsdk:"version"`
+	Source             types.String `tfsdk:"source"`
+	IntegrationJSON    types.String `tfsdk:"integration_json"`
+	EssentialsJSON     types.String `tfsdk:"essentials_json"`
+	ComputeCopilotJSON types.String `tfsdk:"compute_copilot_json"`
+	WAFRJSON           types.String `tfsdk:"wafr_json"`
+	SystemBucketJSON   types.String `tfsdk:"system_bucket_json"`
+}
+
+// Metadata returns the data source type name.
+func (d *iamPolicyDocumentDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_iam_policy_document"
+}
+
+// Schema defines the schema for the data source.
+func (d *iamPolicyDocumentDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "The IAM policy document datasource returns the IAM policies the nOps integration role needs for each nOps feature. " +
+			"The policies are retrieved from the nOps platform, the ones embedded in the provider are used when the platform doesn't publish them or with `offline`.",
+		Attributes: map[string]schema.Attribute{
+			"essentials": schema.BoolAttribute{
+				Optional:    true,
+				Description: "Whether to return the nOps essentials policy",
+			},
+			"compute_copilot": schema.BoolAttribute{
+				Optional:    true,
+				Description: "Whether to return the nOps compute copilot policy",
+			},
+			"wafr": schema.BoolAttribute{
+				Optional:    true,
+				Description: "Whether to return the nOps WAFR policy",
+			},
+			"system_bucket": schema.BoolAttribute{
+				Optional:    true,
+				Description: "Whether to return the policy granting access to the nOps system bucket, requires `bucket_name`",
+			},
+			"bucket_name": schema.StringAttribute{
+				Optional:    true,
+				Description: "Name of the nOps system bucket",
+			},
+			"offline": schema.BoolAttribute{
+				Optional:    true,
+				Description: "Use the policies embedded in the provider without calling the nOps API",
+			},
+			"version": schema.StringAttribute{
+				Computed:    true,
+				Description: "Version of the returned policies",
+			},
+			"source": schema.StringAttribute{
+				Computed:    true,
+				Description: "Where the policies come from, `" + iamPolicySourcePlatform + "` or `" + iamPolicySourceEmbedded + "`",
+			},
+			"integration_json": schema.StringAttribute{
+				Computed:    true,
+				Description: "Policy required by every nOps integration",
+			},
+			"essentials_json": schema.StringAttribute{
+				Computed:    true,
+				Description: "nOps essentials policy, null unless `essentials` is set",
+			},
+			"compute_copilot_json": schema.StringAttribute{
+				Computed:    true,
+				Description: "nOps compute copilot policy, null unless `compute_copilot` is set",
+			},
+			"wafr_json": schema.StringAttribute{
+				Computed:    true,
+				Description: "nOps WAFR policy, null unless `wafr` is set",
+			},
+			"system_bucket_json": schema.StringAttribute{
+				Computed:    true,
+				Description: "nOps system bucket policy, null unless `system_bucket` is set",
+			},
+		},
+	}
+}
+
+// Read refreshes the Terraform state with the latest data.
+func (d *iamPolicyDocumentDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var state iamPolicyDocumentDataSourceModel
+	diags := req.Config.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if state.SystemBucket.ValueBool() && state.BucketName.ValueString() == "" {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("bucket_name"),
+			"Missing nOps system bucket name",
+			"A bucket_name must be provided together with system_bucket.",
+		)
+		return
+	}
+
+	catalog, source := d.catalog(ctx, state.Offline.ValueBool(), resp)
+	if catalog == nil {
+		return
+	}
+	state.Version = types.StringValue(catalog.Version)
+	state.Source = types.StringValue(source)
+
+	for _, policy := range []struct {
+		feature string
+		enabled bool
+		target  *types.String
+	}{
+		{IAMPolicyIntegration, true, &state.IntegrationJSON},
+		{IAMPolicyEssentials, state.Essentials.ValueBool(), &state.EssentialsJSON},
+		{IAMPolicyComputeCopilot, state.ComputeCopilot.ValueBool(), &state.ComputeCopilotJSON},
+		{IAMPolicyWAFR, state.WAFR.ValueBool(), &state.WAFRJSON},
+		{IAMPolicySystemBucket, state.SystemBucket.ValueBool(), &state.SystemBucketJSON},
+	} {
+		*policy.target = types.StringNull()
+		if !policy.enabled {
+			continue
+		}
+
+		document, err := catalog.PolicyDocument(policy.feature, state.BucketName.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Error rendering nOps IAM policy",
+				err.Error(),
+			)
+			return
+		}
+		*policy.target = types.StringValue(document)
+	}
+
+	// Set state
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+}
+
+// catalog returns the platform policy catalog, or the embedded one when offline
+// or when the platform doesn't publish a catalog. Any other failure is an error
+// rather than a fallback, as switching catalogs between runs rewrites the
+// policies of the integration role.
+func (d *iamPolicyDocumentDataSource) catalog(ctx context.Context, offline bool, resp *datasource.ReadResponse) (*IAMPolicyCatalog, string) {
+	if !offline {
+		catalog, err := d.client.GetIAMPolicyCatalog(ctx)
+		switch {
+		case err == nil:
+			if err := catalog.Validate(); err != nil {
+				resp.Diagnostics.AddError(
+					"Invalid nOps IAM policies",
+					"The IAM policies returned by the nOps platform are invalid: "+err.Error(),
+				)
+				return nil, ""
+			}
+			return catalog, iamPolicySourcePlatform
+		case IsNotFound(err):
+			tflog.Debug(ctx, "The nOps platform doesn't publish IAM policies, using the embedded ones")
+		default:
+			resp.Diagnostics.AddError(
+				"Error retrieving nOps IAM policies",
+				"The IAM policies could not be retrieved from the nOps platform. "+
+					"Set offline to true to use the ones embedded in this provider version instead.\n\n"+
+					"nOps Client Error: "+err.Error(),
+			)
+			return nil, ""
+		}
+	}
+
+	catalog, err := EmbeddedIAMPolicyCatalog()
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error loading embedded nOps IAM policies",
+			fmt.Sprintf("%s. Please report this issue to the provider developers.", err),
+		)
+		return nil, ""
+	}
+	return catalog, iamPolicySourceEmbedded
+}
+
+// Configure adds the provider configured client to the data source.
+func (d *iamPolicyDocumentDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	// Add a nil check when handling ProviderData because Terraform
+	// sets that data after it calls the ConfigureProvider RPC.
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*Client)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *Client, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	d.client = client
+}
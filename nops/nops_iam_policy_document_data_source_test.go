@@ -0,0 +1,103 @@
+package nops
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+
+	"terraform-provider-nops/nops/internal/fakenops"
+)
+
+func TestIAMPolicyDocumentDataSourceFakeAPI(t *testing.T) {
+	server := newTestAccFakeServer(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// The fake API has no catalog, the embedded one is used.
+			{
+				Config: fakeProviderConfig(server) + `
+data "nops_iam_policy_document" "test" {
+  essentials    = true
+  system_bucket = true
+  bucket_name   = "nops-1-2-471112641702"
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.nops_iam_policy_document.test", "source", "embedded"),
+					resource.TestCheckResourceAttrSet("data.nops_iam_policy_document.test", "integration_json"),
+					resource.TestCheckResourceAttr("data.nops_iam_policy_document.test", "essentials_json",
+						`{"Statement":[{"Action":["cloudwatch:ListMetrics","events:CreateEventBus"],"Effect":"Allow","Resource":"*"}],"Version":"2012-10-17"}`),
+					resource.TestCheckNoResourceAttr("data.nops_iam_policy_document.test", "wafr_json"),
+					resource.TestCheckResourceAttrSet("data.nops_iam_policy_document.test", "system_bucket_json"),
+				),
+			},
+			// Platform failures are reported instead of switching to the embedded policies.
+			{
+				PreConfig: func() {
+					server.InjectFault(fakenops.Fault{Method: "GET", PathPrefix: "/c/admin/iam_policies/", Status: 403, Count: 1})
+				},
+				Config: fakeProviderConfig(server) + `
+data "nops_iam_policy_document" "test" {
+  wafr = true
+}
+`,
+				ExpectError: regexp.MustCompile(`Error retrieving nOps IAM policies`),
+			},
+			{
+				PreConfig: func() {
+					server.IAMPolicies = []byte(`{"version": "platform-test", "features": {
+  "integration": {"actions": ["ce:GetCostAndUsage"]},
+  "essentials": {"actions": ["cloudwatch:ListMetrics"]},
+  "compute_copilot": {"actions": ["lambda:InvokeFunction"]},
+  "wafr": {"actions": ["wellarchitected:*"]},
+  "system_bucket": {"actions": ["s3:GetObject"], "resources": ["arn:aws:s3:::{bucket_name}/*"]}
+}}`)
+				},
+				Config: fakeProviderConfig(server) + `
+data "nops_iam_policy_document" "test" {
+  wafr = true
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.nops_iam_policy_document.test", "source", "platform"),
+					resource.TestCheckResourceAttr("data.nops_iam_policy_document.test", "version", "platform-test"),
+					resource.TestCheckResourceAttr("data.nops_iam_policy_document.test", "wafr_json",
+						`{"Statement":[{"Action":["wellarchitected:*"],"Effect":"Allow","Resource":"*"}],"Version":"2012-10-17"}`),
+				),
+			},
+		},
+	})
+}
+
+func TestIAMPolicyCatalogFallback(t *testing.T) {
+	for status, fallback := range map[int]bool{
+		http.StatusNotFound:            true,
+		http.StatusUnauthorized:        false,
+		http.StatusForbidden:           false,
+		http.StatusInternalServerError: false,
+		http.StatusServiceUnavailable:  false,
+	} {
+		client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(status)
+		})
+		client.MaxRetries = 1
+		d := &iamPolicyDocumentDataSource{client: client}
+
+		resp := &datasource.ReadResponse{}
+		catalog, source := d.catalog(context.Background(), false, resp)
+		if fallback {
+			if catalog == nil || source != iamPolicySourceEmbedded || resp.Diagnostics.HasError() {
+				t.Errorf("%d: expected the embedded catalog, got %q: %v", status, source, resp.Diagnostics)
+			}
+			continue
+		}
+		if catalog != nil || !resp.Diagnostics.HasError() {
+			t.Errorf("%d: expected an error instead of a fallback to the %q catalog", status, source)
+		}
+	}
+}
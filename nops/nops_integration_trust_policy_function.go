@@ -0,0 +1,80 @@
+package nops
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var _ function.Function = &integrationTrustPolicyFunction{}
+
+func NewIntegrationTrustPolicyFunction() function.Function {
+	return &integrationTrustPolicyFunction{}
+}
+
+// Function implementation.
+type integrationTrustPolicyFunction struct{}
+
+// Metadata returns the function name.
+func (f *integrationTrustPolicyFunction) Metadata(_ context.Context, _ function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "integration_trust_policy"
+}
+
+// Definition defines the parameters and return type of the function.
+func (f *integrationTrustPolicyFunction) Definition(_ context.Context, _ function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary: "Trust policy of the nOps integration role",
+		MarkdownDescription: "Returns the JSON trust policy allowing nOps to assume the integration role with `sts:AssumeRole`, restricted to the `external_id` of the project. " +
+			"The nOps principal is the one of the optional `deployment`, `" + DefaultDeployment + "` by default, one of: `" + strings.Join(DeploymentNames(), "`, `") + "`. " +
+			"Provider functions can't read the provider configuration, pass the same `deployment` as the provider when it isn't the default one.",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:        "external_id",
+				Description: "External ID of the nOps project",
+			},
+		},
+		VariadicParameter: function.StringParameter{
+			Name:        "deployment",
+			Description: "nOps deployment, at most one",
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+// Run returns the trust policy document.
+func (f *integrationTrustPolicyFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var (
+		externalID  string
+		deployments []string
+	)
+
+	resp.Error = req.Arguments.Get(ctx, &externalID, &deployments)
+	if resp.Error != nil {
+		return
+	}
+
+	if len(deployments) > 1 {
+		resp.Error = function.NewArgumentFuncError(1, fmt.Sprintf("At most one deployment may be given, got %d.", len(deployments)))
+		return
+	}
+	name := DefaultDeployment
+	if len(deployments) == 1 {
+		name = deployments[0]
+	}
+	deployment, ok := LookupDeployment(name)
+	if !ok {
+		resp.Error = function.NewArgumentFuncError(1, fmt.Sprintf("The deployment %q is not supported, expected one of: %s.", name, strings.Join(DeploymentNames(), ", ")))
+		return
+	}
+
+	policy, err := IntegrationTrustPolicy(externalID, deployment)
+	if err != nil {
+		resp.Error = function.NewFuncError(err.Error())
+		return
+	}
+
+	resp.Error = resp.Result.Set(ctx, policy)
+}
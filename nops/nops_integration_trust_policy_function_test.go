@@ -0,0 +1,63 @@
+package nops
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+)
+
+func TestIntegrationTrustPolicyFunction(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_8_0),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+locals {
+  handwritten = jsonencode({
+    Version = "2012-10-17"
+    Statement = [
+      {
+        Effect = "Allow"
+        Principal = {
+          AWS = "arn:aws:iam::202279780353:root"
+        }
+        Action = "sts:AssumeRole"
+        Condition = {
+          StringEquals = {
+            "sts:ExternalId" = "a1b2c3d4-e5f6"
+          }
+        }
+      }
+    ]
+  })
+}
+
+output "matches_handwritten" {
+  value = provider::nops::integration_trust_policy("a1b2c3d4-e5f6") == local.handwritten
+}
+
+output "principal" {
+  value = jsondecode(provider::nops::integration_trust_policy("a1b2c3d4-e5f6", "us")).Statement[0].Principal.AWS
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckOutput("matches_handwritten", "true"),
+					resource.TestCheckOutput("principal", "arn:aws:iam::202279780353:root"),
+				),
+			},
+			{
+				Config: `
+output "invalid" {
+  value = provider::nops::integration_trust_policy("a1b2c3d4-e5f6", "mars")
+}
+`,
+				ExpectError: regexp.MustCompile("is not supported"),
+			},
+		},
+	})
+}
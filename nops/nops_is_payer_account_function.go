@@ -0,0 +1,68 @@
+package nops
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var _ function.Function = &isPayerAccountFunction{}
+
+func NewIsPayerAccountFunction() function.Function {
+	return &isPayerAccountFunction{}
+}
+
+// Function implementation.
+type isPayerAccountFunction struct{}
+
+// Metadata returns the function name.
+func (f *isPayerAccountFunction) Metadata(_ context.Context, _ function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "is_payer_account"
+}
+
+// Definition defines the parameters and return type of the function.
+func (f *isPayerAccountFunction) Definition(_ context.Context, _ function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary: "Whether an account is the master payer account",
+		MarkdownDescription: "Returns whether `account_id` is the master payer account of its AWS organization, which holds the nOps system bucket. " +
+			"A `master_payer_account_number` of `" + NotApplicable + "` or an empty string, for accounts outside of an organization, is never a match.",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:        "account_id",
+				Description: "AWS account number to check",
+			},
+			function.StringParameter{
+				Name:        "master_payer_account_number",
+				Description: "Master payer AWS account number of the organization",
+			},
+		},
+		Return: function.BoolReturn{},
+	}
+}
+
+// Run reports whether the account is the master payer account.
+func (f *isPayerAccountFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var accountID, masterPayerAccountNumber string
+
+	resp.Error = req.Arguments.Get(ctx, &accountID, &masterPayerAccountNumber)
+	if resp.Error != nil {
+		return
+	}
+
+	if !isAWSAccountID(accountID) {
+		resp.Error = function.NewArgumentFuncError(0, fmt.Sprintf("%q is not a 12 digit AWS account ID.", accountID))
+		return
+	}
+	if masterPayerAccountNumber == "" || masterPayerAccountNumber == NotApplicable {
+		resp.Error = resp.Result.Set(ctx, false)
+		return
+	}
+	if !isAWSAccountID(masterPayerAccountNumber) {
+		resp.Error = function.NewArgumentFuncError(1, fmt.Sprintf("%q is not a 12 digit AWS account ID.", masterPayerAccountNumber))
+		return
+	}
+
+	resp.Error = resp.Result.Set(ctx, accountID == masterPayerAccountNumber)
+}
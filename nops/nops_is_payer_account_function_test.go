@@ -0,0 +1,48 @@
+package nops
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+)
+
+func TestIsPayerAccountFunction(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_8_0),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+output "payer" {
+  value = provider::nops::is_payer_account("471112641702", "471112641702")
+}
+
+output "member" {
+  value = provider::nops::is_payer_account("471112641702", "202279780353")
+}
+
+output "standalone" {
+  value = provider::nops::is_payer_account("471112641702", "na")
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckOutput("payer", "true"),
+					resource.TestCheckOutput("member", "false"),
+					resource.TestCheckOutput("standalone", "false"),
+				),
+			},
+			{
+				Config: `
+output "invalid" {
+  value = provider::nops::is_payer_account("xxxxxx", "471112641702")
+}
+`,
+				ExpectError: regexp.MustCompile("not a 12 digit AWS account ID"),
+			},
+		},
+	})
+}
@@ -6,17 +6,20 @@ import (
 	"strconv"
 	"time"
 
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 )
 
 // Ensure the implementation satisfies the expected interfaces.
 var (
-	_ resource.Resource              = &projectIntegrationResource{}
-	_ resource.ResourceWithConfigure = &projectIntegrationResource{}
+	_ resource.Resource                   = &projectIntegrationResource{}
+	_ resource.ResourceWithConfigure      = &projectIntegrationResource{}
+	_ resource.ResourceWithValidateConfig = &projectIntegrationResource{}
 )
 
 // projectIntegrationResource is the resource implementation.
@@ -25,12 +28,13 @@ type projectIntegrationResource struct {
 }
 
 type newProjectIntegrationModel struct {
-	ID           types.Int64  `tfsdk:"id"`
-	LastUpdated  types.String `tfsdk:"last_updated"`
-	ExternalID   types.String `tfsdk:"external_id"`
-	AwsAccountID types.String `tfsdk:"aws_account_id"`
-	RoleArn      types.String `tfsdk:"role_arn"`
-	BucketName   types.String `tfsdk:"bucket_name"`
+	ID           types.Int64    `tfsdk:"id"`
+	LastUpdated  types.String   `tfsdk:"last_updated"`
+	ExternalID   types.String   `tfsdk:"external_id"`
+	AwsAccountID types.String   `tfsdk:"aws_account_id"`
+	RoleArn      types.String   `tfsdk:"role_arn"`
+	BucketName   types.String   `tfsdk:"bucket_name"`
+	Timeouts     timeouts.Value `tfsdk:"timeouts"`
 }
 
 // NewprojectIntegrationResource is a helper function to simplify the provider implementation.
@@ -66,7 +70,7 @@ func (r *projectIntegrationResource) Metadata(_ context.Context, req resource.Me
 }
 
 // Schema defines the schema for the resource.
-func (r *projectIntegrationResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+func (r *projectIntegrationResource) Schema(ctx context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
 	resp.Schema = schema.Schema{
 		Description: "Notifies the nOps platform a new account has linked to a project with the required input values." +
 			" This resource is mostly used only for secure connection with nOps APIs.",
@@ -82,10 +86,12 @@ func (r *projectIntegrationResource) Schema(_ context.Context, _ resource.Schema
 			"role_arn": schema.StringAttribute{
 				Required:    true,
 				Description: "AWS IAM role to create/update account integration to nOps",
+				Validators:  []validator.String{iamRoleARNValidator()},
 			},
 			"bucket_name": schema.StringAttribute{
 				Required:    true,
 				Description: "AWS S3 bucket name to be used for CUR reports",
+				Validators:  []validator.String{bucketNameValidator()},
 			},
 			"external_id": schema.StringAttribute{
 				Required:    true,
@@ -94,8 +100,31 @@ func (r *projectIntegrationResource) Schema(_ context.Context, _ resource.Schema
 			"aws_account_id": schema.StringAttribute{
 				Required:    true,
 				Description: "Target AWS account id to integrate with nOps",
+				Validators:  []validator.String{awsAccountIDValidator()},
 			},
 		},
+		Blocks: map[string]schema.Block{
+			// Delete makes no API call, so it has no timeout.
+			"timeouts": timeouts.Block(ctx, timeouts.Opts{
+				Create: true,
+				Read:   true,
+				Update: true,
+			}),
+		},
+	}
+}
+
+// ValidateConfig checks the role belongs to the integrated account.
+func (r *projectIntegrationResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var roleArn, awsAccountID types.String
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("role_arn"), &roleArn)...)
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("aws_account_id"), &awsAccountID)...)
+	if resp.Diagnostics.HasError() || roleArn.IsNull() || roleArn.IsUnknown() || awsAccountID.IsNull() || awsAccountID.IsUnknown() {
+		return
+	}
+
+	if mismatch := roleARNAccountMismatch(roleArn.ValueString(), awsAccountID.ValueString()); mismatch != "" {
+		resp.Diagnostics.AddAttributeError(path.Root("role_arn"), "Invalid nOps Integration Role", mismatch)
 	}
 }
 
@@ -108,6 +137,12 @@ func (r *projectIntegrationResource) Create(ctx context.Context, req resource.Cr
 		return
 	}
 
+	ctx, cancel := withTimeout(ctx, plan.Timeouts.Create, defaultCreateTimeout, &resp.Diagnostics)
+	defer cancel()
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	// Notify nOps with new values
 	var integration Integration
 	integration.RoleArn = plan.RoleArn.ValueString()
@@ -121,7 +156,7 @@ func (r *projectIntegrationResource) Create(ctx context.Context, req resource.Cr
 		RoleArn:       plan.RoleArn.ValueString(),
 		ExternalID:    plan.ExternalID.ValueString(),
 	}
-	_, err := r.client.NotifyNops(integration)
+	_, err := r.client.NotifyNops(ctx, integration)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error notifying nOps",
@@ -131,7 +166,7 @@ func (r *projectIntegrationResource) Create(ctx context.Context, req resource.Cr
 	}
 
 	// Get updated project values from nOps
-	projects, err := r.client.GetProjects()
+	projects, err := r.client.GetProjects(ctx)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error getting remote project data",
@@ -168,7 +203,13 @@ func (r *projectIntegrationResource) Read(ctx context.Context, req resource.Read
 		return
 	}
 
-	projects, err := r.client.GetProjects()
+	ctx, cancel := withTimeout(ctx, state.Timeouts.Read, defaultReadTimeout, &resp.Diagnostics)
+	defer cancel()
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	projects, err := r.client.GetProjects(ctx)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error getting remote project data",
@@ -202,6 +243,12 @@ func (r *projectIntegrationResource) Update(ctx context.Context, req resource.Up
 		return
 	}
 
+	ctx, cancel := withTimeout(ctx, plan.Timeouts.Update, defaultUpdateTimeout, &resp.Diagnostics)
+	defer cancel()
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	// Notify nOps with updated values
 	var integration Integration
 	integration.RoleArn = plan.RoleArn.ValueString()
@@ -215,7 +262,7 @@ func (r *projectIntegrationResource) Update(ctx context.Context, req resource.Up
 		RoleArn:       plan.RoleArn.ValueString(),
 		ExternalID:    plan.ExternalID.ValueString(),
 	}
-	_, err := r.client.NotifyNops(integration)
+	_, err := r.client.NotifyNops(ctx, integration)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error updating nOps project",
@@ -225,7 +272,7 @@ func (r *projectIntegrationResource) Update(ctx context.Context, req resource.Up
 	}
 
 	// Get updated project values from nOps
-	projects, err := r.client.GetProjects()
+	projects, err := r.client.GetProjects(ctx)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error getting remote project data",
@@ -4,11 +4,17 @@ import (
 	"context"
 	"fmt"
 	"strconv"
+	"strings"
 	"time"
 
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 )
@@ -26,16 +32,18 @@ type projectResource struct {
 }
 
 type ProjectModel struct {
-	ID                       types.Int64  `tfsdk:"id"`
-	LastUpdated              types.String `tfsdk:"last_updated"`
-	Name                     types.String `tfsdk:"name"`
-	AccountNumber            types.String `tfsdk:"account_number"`
-	MasterPayerAccountNumber types.String `tfsdk:"master_payer_account_number"`
-	Arn                      types.String `tfsdk:"arn"`
-	Bucket                   types.String `tfsdk:"bucket"`
-	Client                   types.Int64  `tfsdk:"client"`
-	ExternalID               types.String `tfsdk:"external_id"`
-	RoleName                 types.String `tfsdk:"role_name"`
+	ID                       types.Int64    `tfsdk:"id"`
+	LastUpdated              types.String   `tfsdk:"last_updated"`
+	Name                     types.String   `tfsdk:"name"`
+	AccountNumber            types.String   `tfsdk:"account_number"`
+	MasterPayerAccountNumber types.String   `tfsdk:"master_payer_account_number"`
+	Arn                      types.String   `tfsdk:"arn"`
+	Bucket                   types.String   `tfsdk:"bucket"`
+	Client                   types.Int64    `tfsdk:"client"`
+	ExternalID               types.String   `tfsdk:"external_id"`
+	RoleName                 types.String   `tfsdk:"role_name"`
+	AdoptExisting            types.Bool     `tfsdk:"adopt_existing"`
+	Timeouts                 timeouts.Value `tfsdk:"timeouts"`
 }
 
 // NewProjectResource is a helper function to simplify the provider implementation.
@@ -71,13 +79,16 @@ func (r *projectResource) Metadata(_ context.Context, req resource.MetadataReque
 }
 
 // Schema defines the schema for the resource.
-func (r *projectResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+func (r *projectResource) Schema(ctx context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
 	resp.Schema = schema.Schema{
 		Description: "Resource intended to be used for the initial onboarding of an account to the nOps platform, used for communication with nOps APIs.",
 		Attributes: map[string]schema.Attribute{
 			"id": schema.Int64Attribute{
 				Computed:    true,
 				Description: "nOps project identifier.",
+				PlanModifiers: []planmodifier.Int64{
+					int64planmodifier.UseStateForUnknown(),
+				},
 			},
 			"last_updated": schema.StringAttribute{
 				Computed:    true,
@@ -86,36 +97,77 @@ func (r *projectResource) Schema(_ context.Context, _ resource.SchemaRequest, re
 			"name": schema.StringAttribute{
 				Required:    true,
 				Description: "nOps project name",
+				Validators:  projectNameValidators(),
 			},
 			"account_number": schema.StringAttribute{
 				Required:    true,
 				Description: "Target AWS account id to integrate with nOps",
+				Validators:  []validator.String{awsAccountIDValidator()},
 			},
 			"role_name": schema.StringAttribute{
 				Computed:    true,
 				Description: "Name of the IAM role to be used by nOps",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
 			},
 			"master_payer_account_number": schema.StringAttribute{
 				Required:    true,
-				Description: "Master payer AWS account id used to conditionally create resources",
+				Description: "Master payer AWS account id used to conditionally create resources, or `" + NotApplicable + "` for accounts outside of an organization. Changing it re-creates the project",
+				Validators:  []validator.String{masterPayerValidator()},
+				PlanModifiers: []planmodifier.String{
+					// The API can't move a project to another payer, but a project
+					// imported without a known payer only records it in place.
+					stringplanmodifier.RequiresReplaceIf(
+						func(_ context.Context, req planmodifier.StringRequest, resp *stringplanmodifier.RequiresReplaceIfFuncResponse) {
+							resp.RequiresReplace = !req.StateValue.IsNull()
+						},
+						"Changing the master payer account number re-creates the project.",
+						"Changing the master payer account number re-creates the project.",
+					),
+				},
+			},
+			"adopt_existing": schema.BoolAttribute{
+				Optional:    true,
+				Description: "Adopt the project of `account_number` into the state when it already exists and is integrated, instead of failing. The project is renamed to `name` if needed, and adopting fails when nOps reports another `master_payer_account_number`. Only used on create, changing it later only updates the state. Defaults to the provider `adopt_existing_projects` setting.",
 			},
 			"client": schema.Int64Attribute{
 				Computed:    true,
 				Description: "nOps client ID",
+				PlanModifiers: []planmodifier.Int64{
+					int64planmodifier.UseStateForUnknown(),
+				},
 			},
 			"arn": schema.StringAttribute{
 				Computed:    true,
 				Description: "AWS IAM role ARN to create/update account integration to nOps",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
 			},
 			"bucket": schema.StringAttribute{
 				Computed:    true,
 				Description: "AWS S3 bucket name to be used for CUR reports, the initial value is `na`",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
 			},
 			"external_id": schema.StringAttribute{
 				Computed:    true,
 				Description: "Identifier to be used by nOps in order to securely assume a role in the target account",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
 			},
 		},
+		Blocks: map[string]schema.Block{
+			"timeouts": timeouts.Block(ctx, timeouts.Opts{
+				Create: true,
+				Read:   true,
+				Update: true,
+				Delete: true,
+			}),
+		},
 	}
 }
 
@@ -128,7 +180,13 @@ func (r *projectResource) Create(ctx context.Context, req resource.CreateRequest
 		return
 	}
 
-	projects, err := r.client.GetProjects()
+	ctx, cancel := withTimeout(ctx, plan.Timeouts.Create, defaultCreateTimeout, &resp.Diagnostics)
+	defer cancel()
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	projects, err := r.client.GetProjects(ctx)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error: Error getting remote project data",
@@ -137,9 +195,18 @@ func (r *projectResource) Create(ctx context.Context, req resource.CreateRequest
 		return
 	}
 
+	adoptExisting := r.client.AdoptExistingProjects
+	if !plan.AdoptExisting.IsNull() {
+		adoptExisting = plan.AdoptExisting.ValueBool()
+	}
+
 	for _, project := range projects {
 		if types.StringValue(project.AccountNumber) == plan.AccountNumber && project.RoleName != "na" {
 			// Check if the project has already been onboarded for this AWS account and has a role assigned(finished being integrated)
+			if adoptExisting {
+				r.adoptProject(ctx, project, &plan, resp)
+				return
+			}
 			resp.Diagnostics.AddError(
 				fmt.Sprintf("Error: a project already exists for this AWS account %s with ID %d, please review or import by following this documentation: https://help.nops.io/docs/getting-started/Onboarding/onboarding-aws-with-terraform/#importing-existing-nops-projects", plan.AccountNumber, project.ID),
 				fmt.Sprintf("Project found for AWS account %s", plan.AccountNumber),
@@ -174,12 +241,25 @@ func (r *projectResource) Create(ctx context.Context, req resource.CreateRequest
 	newProject.Name = plan.Name.ValueString()
 	newProject.AccountNumber = plan.AccountNumber.ValueString()
 	newProject.MasterPayerAccountNumber = plan.MasterPayerAccountNumber.ValueString()
-	project, err := r.client.CreateProject(newProject)
+	project, err := r.client.CreateProject(ctx, newProject)
 	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error creating project",
-			"Could not create project, unexpected error: "+err.Error(),
-		)
+		switch {
+		case IsConflict(err):
+			resp.Diagnostics.AddError(
+				"Error creating project",
+				fmt.Sprintf("A project already exists in nOps for AWS account %s, please review or import it by following this documentation: https://help.nops.io/docs/getting-started/Onboarding/onboarding-aws-with-terraform/#importing-existing-nops-projects\n\n%s", plan.AccountNumber.ValueString(), err.Error()),
+			)
+		case IsValidationError(err):
+			resp.Diagnostics.AddError(
+				"Error creating project",
+				"The nOps API rejected the project configuration: "+err.Error(),
+			)
+		default:
+			resp.Diagnostics.AddError(
+				"Error creating project",
+				"Could not create project, unexpected error: "+err.Error(),
+			)
+		}
 		return
 	}
 
@@ -202,6 +282,56 @@ func (r *projectResource) Create(ctx context.Context, req resource.CreateRequest
 	}
 }
 
+// adoptProject saves an already integrated project to state, renaming it
+// upstream when its name differs from the plan.
+func (r *projectResource) adoptProject(ctx context.Context, project Project, plan *ProjectModel, resp *resource.CreateResponse) {
+	// A project of another organization would otherwise be silently recorded
+	// with the configured payer, a missing one is taken from the configuration.
+	if project.MasterPayerAccountNumber != "" && project.MasterPayerAccountNumber != plan.MasterPayerAccountNumber.ValueString() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("master_payer_account_number"),
+			"Error adopting project",
+			fmt.Sprintf("Project %d of AWS account %s has master payer account number %s in nOps, not %s. Fix master_payer_account_number or import the project instead.",
+				project.ID, project.AccountNumber, project.MasterPayerAccountNumber, plan.MasterPayerAccountNumber.ValueString()),
+		)
+		return
+	}
+
+	if types.StringValue(project.Name) != plan.Name {
+		updated, err := r.client.UpdateProject(ctx, int64(project.ID), UpdateProject{
+			Name:          plan.Name.ValueString(),
+			AccountNumber: project.AccountNumber,
+		})
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Error adopting project",
+				fmt.Sprintf("Could not rename project %d to %s: %s", project.ID, plan.Name.ValueString(), err.Error()),
+			)
+			return
+		}
+		project.Name = updated.Name
+	}
+
+	tflog.Debug(ctx, fmt.Sprintf("Adopting integrated project %d for account number %s", project.ID, project.AccountNumber))
+	plan.ID = types.Int64Value(int64(project.ID))
+	plan.Name = types.StringValue(project.Name)
+	plan.Client = types.Int64Value(int64(project.Client))
+	plan.Arn = types.StringValue(project.Arn)
+	plan.Bucket = types.StringValue(project.Bucket)
+	plan.AccountNumber = types.StringValue(project.AccountNumber)
+	plan.ExternalID = types.StringValue(project.ExternalID)
+	plan.RoleName = types.StringValue(project.RoleName)
+	plan.LastUpdated = types.StringValue(time.Now().Format(time.RFC850))
+
+	resp.Diagnostics.AddWarning(
+		"Adopted existing nOps project",
+		fmt.Sprintf("Project %d already exists and is integrated for AWS account %s, it was adopted into the state instead of being created.", project.ID, project.AccountNumber),
+	)
+
+	// Set state to fully populated data
+	resp.Diagnostics.Append(resp.State.Set(ctx, plan)...)
+}
+
 // Read refreshes the Terraform state with the latest data.
 func (r *projectResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
 	var state ProjectModel
@@ -211,7 +341,13 @@ func (r *projectResource) Read(ctx context.Context, req resource.ReadRequest, re
 		return
 	}
 
-	projects, err := r.client.GetProjects()
+	ctx, cancel := withTimeout(ctx, state.Timeouts.Read, defaultReadTimeout, &resp.Diagnostics)
+	defer cancel()
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	projects, err := r.client.GetProjects(ctx)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error getting remote project data",
@@ -235,7 +371,14 @@ func (r *projectResource) Read(ctx context.Context, req resource.ReadRequest, re
 		}
 	}
 	if !existingProject {
-		resp.Diagnostics.AddError(fmt.Sprintf("Project %s wasn't found in nOps, please check or remove from state", state.ID.String()), "Project not found")
+		if r.client.StrictProjectReads {
+			resp.Diagnostics.AddError(fmt.Sprintf("Project %s wasn't found in nOps, please check or remove from state", state.ID.String()), "Project not found")
+			return
+		}
+		// Deleted outside of Terraform, drop it from state so it gets planned for re-creation.
+		tflog.Warn(ctx, fmt.Sprintf("Project %d not found in nOps, removing from state", state.ID.ValueInt64()))
+		resp.State.RemoveResource(ctx)
+		return
 	}
 
 	// Set refreshed state
@@ -246,13 +389,72 @@ func (r *projectResource) Read(ctx context.Context, req resource.ReadRequest, re
 	}
 }
 
+// ImportState imports an existing project by its nOps ID, or by its AWS
+// account number or name as account:<account number> or name:<project name>.
 func (r *projectResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
-	// Capability to import existing projects into the TF state without recreation.
-	val, err := strconv.Atoi(req.ID)
+	projects, err := r.client.GetProjects(ctx)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error getting remote project data",
+			err.Error(),
+		)
+		return
+	}
+
+	project, err := findImportedProject(projects, req.ID)
 	if err != nil {
-		resp.Diagnostics.AddError("Error parsing ID for import, please check for a correct project ID", err.Error())
+		resp.Diagnostics.AddError("Error importing project", err.Error())
+		return
+	}
+
+	tflog.Debug(ctx, fmt.Sprintf("Importing project %d for account number %s", project.ID, project.AccountNumber))
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), int64(project.ID))...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("name"), project.Name)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("account_number"), project.AccountNumber)...)
+	if project.MasterPayerAccountNumber != "" {
+		resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("master_payer_account_number"), project.MasterPayerAccountNumber)...)
+	}
+}
+
+// findImportedProject returns the project matching an import ID, failing when
+// none or several projects match.
+func findImportedProject(projects []Project, importID string) (*Project, error) {
+	var match func(Project) bool
+	kind, value, ok := strings.Cut(importID, ":")
+	switch {
+	case !ok:
+		id, err := strconv.Atoi(importID)
+		if err != nil {
+			return nil, fmt.Errorf("expected a project ID, account:<AWS account number> or name:<project name>, got %q", importID)
+		}
+		match = func(p Project) bool { return p.ID == id }
+	case kind == "account":
+		match = func(p Project) bool { return p.AccountNumber == value }
+	case kind == "name":
+		match = func(p Project) bool { return p.Name == value }
+	default:
+		return nil, fmt.Errorf("unsupported import ID prefix %q, expected account: or name:", kind+":")
+	}
+
+	var found []Project
+	for _, project := range projects {
+		if match(project) {
+			found = append(found, project)
+		}
+	}
+
+	switch len(found) {
+	case 0:
+		return nil, fmt.Errorf("no project matches %q in nOps", importID)
+	case 1:
+		return &found[0], nil
+	default:
+		ids := make([]string, 0, len(found))
+		for _, project := range found {
+			ids = append(ids, strconv.Itoa(project.ID))
+		}
+		return nil, fmt.Errorf("%q matches several projects (%s), import one of them by its ID instead", importID, strings.Join(ids, ", "))
 	}
-	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), val)...)
 }
 
 // Update updates the resource and sets the updated Terraform state on success.
@@ -270,19 +472,38 @@ func (r *projectResource) Update(ctx context.Context, req resource.UpdateRequest
 		return
 	}
 
+	ctx, cancel := withTimeout(ctx, plan.Timeouts.Update, defaultUpdateTimeout, &resp.Diagnostics)
+	defer cancel()
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	// We only allow updating name and account number in the project, the rest is handled by the integration.
+	// A new master payer re-creates the project, except when recording one missing from an import. That and
+	// adopt_existing, only used on create, are saved to state without calling the API.
 	// We get the updated values from the response as well.
 	updateProjectRequest := UpdateProject{}
 	updateProjectRequest.Name = plan.Name.ValueString()
 	updateProjectRequest.AccountNumber = plan.AccountNumber.ValueString()
 
-	project, err := r.client.UpdateProject(state.ID.ValueInt64(), updateProjectRequest)
-	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error updating project",
-			err.Error(),
-		)
-		return
+	project := &Project{ID: int(state.ID.ValueInt64()), Name: updateProjectRequest.Name, AccountNumber: updateProjectRequest.AccountNumber}
+	if !plan.Name.Equal(state.Name) || !plan.AccountNumber.Equal(state.AccountNumber) {
+		var err error
+		project, err = r.client.UpdateProject(ctx, state.ID.ValueInt64(), updateProjectRequest)
+		if err != nil {
+			if IsNotFound(err) {
+				resp.Diagnostics.AddError(
+					"Error updating project",
+					fmt.Sprintf("Project %d no longer exists in nOps, refresh the state to plan its re-creation: %s", state.ID.ValueInt64(), err.Error()),
+				)
+				return
+			}
+			resp.Diagnostics.AddError(
+				"Error updating project",
+				err.Error(),
+			)
+			return
+		}
 	}
 
 	// Set values to updated fields in nOps
@@ -316,7 +537,17 @@ func (r *projectResource) Delete(ctx context.Context, req resource.DeleteRequest
 		return
 	}
 
-	err := r.client.DeleteProject(state.ID.ValueInt64())
+	ctx, cancel := withTimeout(ctx, state.Timeouts.Delete, defaultDeleteTimeout, &resp.Diagnostics)
+	defer cancel()
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	err := r.client.DeleteProject(ctx, state.ID.ValueInt64())
+	if IsNotFound(err) {
+		tflog.Warn(ctx, fmt.Sprintf("Project %d was already deleted in nOps", state.ID.ValueInt64()))
+		return
+	}
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error deleting project",
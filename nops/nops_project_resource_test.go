@@ -1,14 +1,23 @@
 package nops
 
 import (
+	"fmt"
+	"regexp"
+	"strconv"
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
+	"github.com/hashicorp/terraform-plugin-testing/plancheck"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+	"github.com/hashicorp/terraform-plugin-testing/tfjsonpath"
+
+	"terraform-provider-nops/nops/internal/fakenops"
 )
 
 func TestProjectResource(t *testing.T) {
 	resource.Test(t, resource.TestCase{
-		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactoriesWithVCR(t),
 		Steps: []resource.TestStep{
 			// Create and Read testing
 			{
@@ -47,3 +56,313 @@ resource "nops_project" "test" {
 		},
 	})
 }
+
+func TestProjectResourceFakeAPI(t *testing.T) {
+	server := newTestAccFakeServer(t)
+	discovered := server.AddDiscoveredProject("210987654321", "discovered")
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		CheckDestroy: func(_ *terraform.State) error {
+			if projects := server.Projects(); len(projects) != 0 {
+				return fmt.Errorf("expected every project to be deleted, found %+v", projects)
+			}
+			return nil
+		},
+		Steps: []resource.TestStep{
+			// Create, with the first listing throttled, and adopt a project discovered by the platform
+			{
+				PreConfig: func() {
+					server.InjectFault(fakenops.Fault{Method: "GET", PathPrefix: "/c/admin/projectaws/", Status: 429, RetryAfter: "0", Count: 2})
+				},
+				Config: fakeProviderConfig(server) + `
+resource "nops_project" "test" {
+  name                        = "automated-testing"
+  account_number              = "123456789012"
+  master_payer_account_number = "123456789012"
+}
+
+resource "nops_project" "discovered" {
+  name                        = "discovered"
+  account_number              = "210987654321"
+  master_payer_account_number = "123456789012"
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("nops_project.test", "name", "automated-testing"),
+					resource.TestCheckResourceAttr("nops_project.test", "role_name", "na"),
+					resource.TestCheckResourceAttr("nops_project.test", "bucket", "na"),
+					resource.TestCheckResourceAttr("nops_project.test", "client", "15418"),
+					resource.TestCheckResourceAttrSet("nops_project.test", "external_id"),
+					resource.TestCheckResourceAttr("nops_project.discovered", "id", strconv.Itoa(discovered.ID)),
+					resource.TestCheckResourceAttr("nops_project.discovered", "external_id", discovered.ExternalID),
+				),
+			},
+			// Update and Read testing
+			{
+				Config: fakeProviderConfig(server) + `
+resource "nops_project" "test" {
+  name                        = "automated-testing-updated"
+  account_number              = "123456789012"
+  master_payer_account_number = "123456789012"
+}
+
+resource "nops_project" "discovered" {
+  name                        = "discovered"
+  account_number              = "210987654321"
+  master_payer_account_number = "123456789012"
+}
+`,
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("nops_project.test", plancheck.ResourceActionUpdate),
+						plancheck.ExpectKnownValue("nops_project.test", tfjsonpath.New("external_id"), knownvalue.NotNull()),
+						plancheck.ExpectKnownValue("nops_project.test", tfjsonpath.New("role_name"), knownvalue.StringExact("na")),
+					},
+				},
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("nops_project.test", "name", "automated-testing-updated"),
+					func(_ *terraform.State) error {
+						for _, project := range server.Projects() {
+							if project.AccountNumber == "123456789012" && project.Name != "automated-testing-updated" {
+								return fmt.Errorf("project was not renamed upstream: %+v", project)
+							}
+						}
+						return nil
+					},
+				),
+			},
+			// Changing the master payer re-creates the project
+			{
+				Config: fakeProviderConfig(server) + `
+resource "nops_project" "test" {
+  name                        = "automated-testing-updated"
+  account_number              = "123456789012"
+  master_payer_account_number = "210987654321"
+}
+
+resource "nops_project" "discovered" {
+  name                        = "discovered"
+  account_number              = "210987654321"
+  master_payer_account_number = "123456789012"
+}
+`,
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("nops_project.test", plancheck.ResourceActionDestroyBeforeCreate),
+						plancheck.ExpectResourceAction("nops_project.discovered", plancheck.ResourceActionNoop),
+					},
+				},
+				Check: resource.TestCheckResourceAttr("nops_project.test", "master_payer_account_number", "210987654321"),
+			},
+			// Import by AWS account number
+			{
+				ResourceName:            "nops_project.test",
+				ImportState:             true,
+				ImportStateId:           "account:123456789012",
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"last_updated"},
+			},
+			// Deleted outside of Terraform, strict reads report it
+			{
+				PreConfig: func() {
+					server.DeleteProject(discovered.ID)
+				},
+				Config: fakeStrictProviderConfig(server) + `
+resource "nops_project" "test" {
+  name                        = "automated-testing-updated"
+  account_number              = "123456789012"
+  master_payer_account_number = "210987654321"
+}
+
+resource "nops_project" "discovered" {
+  name                        = "discovered"
+  account_number              = "210987654321"
+  master_payer_account_number = "123456789012"
+}
+`,
+				ExpectError: regexp.MustCompile(`wasn't found in nOps`),
+			},
+			// Otherwise the resource is removed from state and re-created
+			{
+				Config: fakeProviderConfig(server) + `
+resource "nops_project" "test" {
+  name                        = "automated-testing-updated"
+  account_number              = "123456789012"
+  master_payer_account_number = "210987654321"
+}
+
+resource "nops_project" "discovered" {
+  name                        = "discovered"
+  account_number              = "210987654321"
+  master_payer_account_number = "123456789012"
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("nops_project.discovered", "account_number", "210987654321"),
+					func(state *terraform.State) error {
+						if id := state.RootModule().Resources["nops_project.discovered"].Primary.ID; id == strconv.Itoa(discovered.ID) {
+							return fmt.Errorf("expected project %s to be re-created", id)
+						}
+						return nil
+					},
+				),
+			},
+		},
+	})
+}
+
+func TestFindImportedProject(t *testing.T) {
+	projects := []Project{
+		{ID: 1, AccountNumber: "123456789012", Name: "prod-payer"},
+		{ID: 2, AccountNumber: "210987654321", Name: "shared"},
+		{ID: 3, AccountNumber: "210987654322", Name: "shared"},
+	}
+
+	for importID, want := range map[string]int{
+		"1":                    1,
+		"account:210987654321": 2,
+		"name:prod-payer":      1,
+	} {
+		project, err := findImportedProject(projects, importID)
+		if err != nil {
+			t.Errorf("%s: %s", importID, err)
+			continue
+		}
+		if project.ID != want {
+			t.Errorf("%s: expected project %d, got %d", importID, want, project.ID)
+		}
+	}
+
+	for _, importID := range []string{"4", "prod-payer", "account:000000000000", "name:shared", "id:1"} {
+		if _, err := findImportedProject(projects, importID); err == nil {
+			t.Errorf("%s: expected an error", importID)
+		}
+	}
+}
+
+func TestProjectResourceImportWithoutMasterPayerFakeAPI(t *testing.T) {
+	server := newTestAccFakeServer(t)
+	// Discovered projects don't report their master payer.
+	discovered := server.AddDiscoveredProject("210987654321", "discovered")
+	config := fakeProviderConfig(server) + `
+resource "nops_project" "discovered" {
+  name                        = "discovered"
+  account_number              = "210987654321"
+  master_payer_account_number = "123456789012"
+}
+`
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config:             config,
+				ResourceName:       "nops_project.discovered",
+				ImportState:        true,
+				ImportStateId:      "account:210987654321",
+				ImportStatePersist: true,
+				ImportStateCheck: func(states []*terraform.InstanceState) error {
+					if len(states) != 1 || states[0].ID != strconv.Itoa(discovered.ID) {
+						return fmt.Errorf("expected project %d to be imported, got %+v", discovered.ID, states)
+					}
+					if payer, ok := states[0].Attributes["master_payer_account_number"]; ok && payer != "" {
+						return fmt.Errorf("expected no master payer account number, got %s", payer)
+					}
+					return nil
+				},
+			},
+			// The master payer is recorded in place, without calling the API
+			{
+				Config: config,
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("nops_project.discovered", plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("nops_project.discovered", "id", strconv.Itoa(discovered.ID)),
+					resource.TestCheckResourceAttr("nops_project.discovered", "master_payer_account_number", "123456789012"),
+					func(_ *terraform.State) error {
+						if count := server.RequestCount("PATCH", fmt.Sprintf("/c/admin/projectaws/%d/", discovered.ID)); count != 0 {
+							return fmt.Errorf("expected the project not to be updated upstream, got %d PATCH requests", count)
+						}
+						return nil
+					},
+				),
+			},
+		},
+	})
+}
+
+func TestProjectResourceAdoptExistingFakeAPI(t *testing.T) {
+	server := newTestAccFakeServer(t)
+	integrated := server.AddIntegratedProject("123456789012", "vended", "nops-1-2-123456789012")
+
+	config := func(adoptExisting string) string {
+		return fakeProviderConfig(server) + fmt.Sprintf(`
+resource "nops_project" "test" {
+  name                        = "vended-renamed"
+  account_number              = "123456789012"
+  master_payer_account_number = "123456789012"
+  adopt_existing              = %s
+}
+`, adoptExisting)
+	}
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Integrated projects are refused by default
+			{
+				Config:      config("null"),
+				ExpectError: regexp.MustCompile(`a project already exists for this AWS account`),
+			},
+			// but not from another organization
+			{
+				PreConfig: func() {
+					server.SetMasterPayer(integrated.ID, "210987654321")
+				},
+				Config:      config("true"),
+				ExpectError: regexp.MustCompile(`has master payer account number 210987654321`),
+			},
+			// and adopted and renamed when asked to
+			{
+				PreConfig: func() {
+					server.SetMasterPayer(integrated.ID, "123456789012")
+				},
+				Config: config("true"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("nops_project.test", "id", strconv.Itoa(integrated.ID)),
+					resource.TestCheckResourceAttr("nops_project.test", "name", "vended-renamed"),
+					resource.TestCheckResourceAttr("nops_project.test", "bucket", "nops-1-2-123456789012"),
+					resource.TestCheckResourceAttr("nops_project.test", "role_name", integrated.RoleName),
+					func(_ *terraform.State) error {
+						if project, ok := server.Project(integrated.ID); !ok || project.Name != "vended-renamed" {
+							return fmt.Errorf("project was not renamed upstream: %+v", project)
+						}
+						return nil
+					},
+				),
+			},
+			// adopt_existing is only used on create
+			{
+				Config: config("false"),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("nops_project.test", plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("nops_project.test", "adopt_existing", "false"),
+					func(_ *terraform.State) error {
+						if count := server.RequestCount("PATCH", fmt.Sprintf("/c/admin/projectaws/%d/", integrated.ID)); count != 1 {
+							return fmt.Errorf("expected only the rename to update the project upstream, got %d PATCH requests", count)
+						}
+						return nil
+					},
+				),
+			},
+		},
+	})
+}
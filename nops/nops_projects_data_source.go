@@ -87,7 +87,7 @@ func (d *projectsDataSource) Schema(_ context.Context, _ datasource.SchemaReques
 func (d *projectsDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
 	var state projectsDataSourceModel
 
-	projects, err := d.client.GetProjects()
+	projects, err := d.client.GetProjects(ctx)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error getting remote project data",
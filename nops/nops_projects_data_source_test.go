@@ -8,7 +8,7 @@ import (
 
 func TestProjectsDataSource(t *testing.T) {
 	resource.Test(t, resource.TestCase{
-		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactoriesWithVCR(t),
 		Steps: []resource.TestStep{
 			// Read testing
 			{
@@ -0,0 +1,86 @@
+package nops
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var _ function.Function = &systemBucketNameFunction{}
+
+func NewSystemBucketNameFunction() function.Function {
+	return &systemBucketNameFunction{}
+}
+
+// Function implementation.
+type systemBucketNameFunction struct{}
+
+// Metadata returns the function name.
+func (f *systemBucketNameFunction) Metadata(_ context.Context, _ function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "system_bucket_name"
+}
+
+// Definition defines the parameters and return type of the function.
+func (f *systemBucketNameFunction) Definition(_ context.Context, _ function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary: "Name of the nOps system bucket of an account",
+		MarkdownDescription: "Returns the name of the S3 bucket holding the nOps system data of an account, `nops-<project_id>-<client_id>-<account_id>`. " +
+			"An optional `bucket_name` other than `" + NotApplicable + "` or an empty string is validated and returned instead, for buckets created before the convention.",
+		Parameters: []function.Parameter{
+			function.Int64Parameter{
+				Name:        "project_id",
+				Description: "nOps project identifier, the `id` of the `nops_project`",
+			},
+			function.Int64Parameter{
+				Name:        "client_id",
+				Description: "nOps client identifier, the `client` of the `nops_project`",
+			},
+			function.StringParameter{
+				Name:        "account_id",
+				Description: "AWS account number of the project",
+			},
+		},
+		VariadicParameter: function.StringParameter{
+			Name:        "bucket_name",
+			Description: "Existing system bucket name, at most one",
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+// Run returns the system bucket name.
+func (f *systemBucketNameFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var (
+		projectID, clientID int64
+		accountID           string
+		bucketNames         []string
+	)
+
+	resp.Error = req.Arguments.Get(ctx, &projectID, &clientID, &accountID, &bucketNames)
+	if resp.Error != nil {
+		return
+	}
+
+	if len(bucketNames) > 1 {
+		resp.Error = function.NewArgumentFuncError(3, fmt.Sprintf("At most one bucket_name may be given, got %d.", len(bucketNames)))
+		return
+	}
+	if len(bucketNames) == 1 && bucketNames[0] != "" && bucketNames[0] != NotApplicable {
+		if err := validateBucketName(bucketNames[0]); err != nil {
+			resp.Error = function.NewArgumentFuncError(3, err.Error())
+			return
+		}
+		resp.Error = resp.Result.Set(ctx, bucketNames[0])
+		return
+	}
+
+	name, err := SystemBucketName(projectID, clientID, accountID)
+	if err != nil {
+		resp.Error = function.NewFuncError(err.Error())
+		return
+	}
+
+	resp.Error = resp.Result.Set(ctx, name)
+}
@@ -0,0 +1,56 @@
+package nops
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+)
+
+func TestSystemBucketNameFunction(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_8_0),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+output "generated" {
+  value = provider::nops::system_bucket_name(1234, 15418, "471112641702")
+}
+
+output "not_applicable" {
+  value = provider::nops::system_bucket_name(1234, 15418, "471112641702", "na")
+}
+
+output "existing" {
+  value = provider::nops::system_bucket_name(1234, 15418, "471112641702", "legacy-nops-bucket")
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckOutput("generated", "nops-1234-15418-471112641702"),
+					resource.TestCheckOutput("not_applicable", "nops-1234-15418-471112641702"),
+					resource.TestCheckOutput("existing", "legacy-nops-bucket"),
+				),
+			},
+			{
+				Config: `
+output "invalid" {
+  value = provider::nops::system_bucket_name(1234, 15418, "4711126")
+}
+`,
+				ExpectError: regexp.MustCompile("not a 12 digit AWS account ID"),
+			},
+			{
+				Config: `
+output "invalid" {
+  value = provider::nops::system_bucket_name(1234, 15418, "471112641702", "Invalid_Bucket")
+}
+`,
+				ExpectError: regexp.MustCompile("not a valid S3 bucket name"),
+			},
+		},
+	})
+}
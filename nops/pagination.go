@@ -0,0 +1,137 @@
+package nops
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// maxPages guards against list endpoints returning a never ending chain of next links.
+const maxPages = 1000
+
+// pageEnvelope - paginated list response with `next`/`results` keys.
+type pageEnvelope struct {
+	Next    *string          `json:"next"`
+	Results *json.RawMessage `json:"results"`
+}
+
+// pageIterator walks a list endpoint page by page. Endpoints returning a bare
+// JSON array are treated as a single page, paginated envelopes are followed
+// through their `next` links.
+type pageIterator[T any] struct {
+	client  *Client
+	next    *url.URL
+	page    []T
+	err     error
+	visited map[string]bool
+}
+
+func newPageIterator[T any](c *Client, rawURL string) *pageIterator[T] {
+	it := &pageIterator[T]{
+		client:  c,
+		visited: map[string]bool{},
+	}
+	it.next, it.err = url.Parse(rawURL)
+	return it
+}
+
+// Next fetches the next page, returning false once every page was read or on error.
+func (it *pageIterator[T]) Next(ctx context.Context) bool {
+	if it.err != nil || it.next == nil {
+		return false
+	}
+
+	current := it.next
+	it.next = nil
+	if it.visited[current.String()] || len(it.visited) >= maxPages {
+		it.err = fmt.Errorf("pagination of %s did not terminate after %d pages", current.Path, len(it.visited))
+		return false
+	}
+	it.visited[current.String()] = true
+
+	req, err := http.NewRequestWithContext(ctx, "GET", current.String(), nil)
+	if err != nil {
+		it.err = err
+		return false
+	}
+
+	body, err := it.client.doRequest(req)
+	if err != nil {
+		it.err = err
+		return false
+	}
+
+	it.page, it.next, it.err = decodePage[T](current, body)
+	return it.err == nil
+}
+
+// Page returns the items of the page fetched by the last call to Next.
+func (it *pageIterator[T]) Page() []T {
+	return it.page
+}
+
+// Err returns the error that stopped the iteration, if any.
+func (it *pageIterator[T]) Err() error {
+	return it.err
+}
+
+// decodePage decodes either a bare array or a paginated envelope, returning
+// the resolved URL of the next page when there is one.
+func decodePage[T any](current *url.URL, body []byte) ([]T, *url.URL, error) {
+	items := []T{}
+
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) > 0 && trimmed[0] == '[' {
+		if err := json.Unmarshal(trimmed, &items); err != nil {
+			return nil, nil, err
+		}
+		return items, nil, nil
+	}
+
+	envelope := pageEnvelope{}
+	if err := json.Unmarshal(trimmed, &envelope); err != nil {
+		return nil, nil, err
+	}
+	if envelope.Results == nil {
+		return nil, nil, fmt.Errorf("unexpected list response from %s, expected an array or a paginated object with results", current.Path)
+	}
+	if err := json.Unmarshal(*envelope.Results, &items); err != nil {
+		return nil, nil, err
+	}
+
+	if envelope.Next == nil || *envelope.Next == "" {
+		return items, nil, nil
+	}
+
+	next, err := current.Parse(*envelope.Next)
+	if err != nil {
+		return nil, nil, fmt.Errorf("parsing next page link: %w", err)
+	}
+	// Never send the API credentials anywhere else than the configured host.
+	if next.Hostname() != current.Hostname() {
+		return nil, nil, fmt.Errorf("next page link %q points outside of %s", *envelope.Next, current.Host)
+	}
+	// Links built behind a TLS terminating proxy may advertise plain http.
+	next.Scheme = current.Scheme
+	next.Host = current.Host
+
+	return items, next, nil
+}
+
+// listAll collects the items of every page of a list endpoint.
+func listAll[T any](ctx context.Context, c *Client, rawURL string) ([]T, error) {
+	items := []T{}
+
+	it := newPageIterator[T](c, rawURL)
+	for it.Next(ctx) {
+		items = append(items, it.Page()...)
+	}
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
@@ -0,0 +1,52 @@
+package nops
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestGetProjectsFollowsPaginatedEnvelopes(t *testing.T) {
+	var host string
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Nops-Api-Key") != "test-key" {
+			t.Errorf("page %s was requested without the API key", r.URL)
+		}
+		switch r.URL.Query().Get("page") {
+		case "":
+			fmt.Fprintf(w, `{"count": 3, "next": "http://%s/c/admin/projectaws/?page=2", "results": [{"id": 1}]}`, host)
+		case "2":
+			_, _ = w.Write([]byte(`{"count": 3, "next": "/c/admin/projectaws/?page=3", "results": [{"id": 2}]}`))
+		default:
+			_, _ = w.Write([]byte(`{"count": 3, "next": null, "results": [{"id": 3}]}`))
+		}
+	})
+	host = strings.TrimPrefix(client.HostURL, "http://")
+
+	projects, err := client.GetProjects(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(projects) != 3 || projects[0].ID != 1 || projects[2].ID != 3 {
+		t.Fatalf("unexpected projects: %+v", projects)
+	}
+}
+
+func TestListRejectsUnexpectedPages(t *testing.T) {
+	for name, body := range map[string]string{
+		"foreign host": `{"next": "https://example.com/c/admin/container_cost_bucket/?page=2", "results": []}`,
+		"self loop":    `{"next": "/c/admin/container_cost_bucket/", "results": []}`,
+		"no results":   `{"detail": "ok"}`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write([]byte(body))
+			})
+			if _, err := client.GetContainerCostBucketSetupStatus(context.Background()); err == nil {
+				t.Fatal("expected an error")
+			}
+		})
+	}
+}
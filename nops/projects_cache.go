@@ -0,0 +1,85 @@
+package nops
+
+import (
+	"context"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"golang.org/x/sync/singleflight"
+)
+
+// DefaultProjectsCacheTTL - how long a project listing is shared between resources.
+const DefaultProjectsCacheTTL time.Duration = 30 * time.Second
+
+// projectsCache shares the project listing between the resources and data
+// sources of a single Terraform run. Concurrent readers are collapsed into one
+// API call, and every mutation bumps the generation so in-flight or cached
+// listings taken before it are never served again.
+type projectsCache struct {
+	group singleflight.Group
+
+	mu         sync.Mutex
+	projects   []Project
+	fetched    time.Time
+	generation uint64
+}
+
+// GetProjects - lists the projects of the client, served from a short lived cache.
+func (c *Client) GetProjects(ctx context.Context) ([]Project, error) {
+	cache := &c.projectsCache
+
+	cache.mu.Lock()
+	if c.ProjectsCacheTTL > 0 && cache.projects != nil && time.Since(cache.fetched) < c.ProjectsCacheTTL {
+		projects := slices.Clone(cache.projects)
+		cache.mu.Unlock()
+		tflog.Trace(ctx, "Using cached nOps project listing")
+		return projects, nil
+	}
+	generation := cache.generation
+	cache.mu.Unlock()
+
+	// The listing is shared by every concurrent caller, so it must not be
+	// cancelled with the one that started it. Each caller still stops
+	// waiting on its own context below, and every request of the listing
+	// is bounded by the client request timeout.
+	fetchCtx := context.WithoutCancel(ctx)
+	result := cache.group.DoChan(strconv.FormatUint(generation, 10), func() (any, error) {
+		projects, err := c.fetchProjects(fetchCtx)
+		if err != nil {
+			return nil, err
+		}
+
+		cache.mu.Lock()
+		if cache.generation == generation {
+			cache.projects = projects
+			cache.fetched = time.Now()
+		}
+		cache.mu.Unlock()
+
+		return projects, nil
+	})
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case res := <-result:
+		if res.Err != nil {
+			return nil, res.Err
+		}
+		projects, _ := res.Val.([]Project)
+		return slices.Clone(projects), nil
+	}
+}
+
+// invalidateProjects drops the cached listing after a project was changed.
+func (c *Client) invalidateProjects() {
+	cache := &c.projectsCache
+
+	cache.mu.Lock()
+	cache.projects = nil
+	cache.generation++
+	cache.mu.Unlock()
+}
@@ -0,0 +1,123 @@
+package nops
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestGetProjectsSharesConcurrentListings(t *testing.T) {
+	var calls atomic.Int32
+	release := make(chan struct{})
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		<-release
+		_, _ = w.Write([]byte(`[{"id": 1, "account_number": "123456789012"}]`))
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			projects, err := client.GetProjects(context.Background())
+			if err != nil || len(projects) != 1 {
+				t.Errorf("unexpected result: %v %v", projects, err)
+			}
+		}()
+	}
+
+	// Give every reader the chance to join the in-flight listing.
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if _, err := client.GetProjects(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got := calls.Load(); got != 1 {
+		t.Fatalf("expected a single listing call, got %d", got)
+	}
+}
+
+func TestGetProjectsSurvivesTheFirstCallerCancelling(t *testing.T) {
+	var calls atomic.Int32
+	release := make(chan struct{})
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		<-release
+		_, _ = w.Write([]byte(`[{"id": 1, "account_number": "123456789012"}]`))
+	})
+
+	first, cancel := context.WithCancel(context.Background())
+	firstErr := make(chan error, 1)
+	go func() {
+		_, err := client.GetProjects(first)
+		firstErr <- err
+	}()
+
+	// Let the first caller start the listing before the second joins it.
+	time.Sleep(20 * time.Millisecond)
+	second := make(chan []Project, 1)
+	go func() {
+		projects, err := client.GetProjects(context.Background())
+		if err != nil {
+			t.Errorf("unexpected error: %s", err)
+		}
+		second <- projects
+	}()
+	time.Sleep(20 * time.Millisecond)
+
+	cancel()
+	if err := <-firstErr; err != context.Canceled {
+		t.Fatalf("expected the first caller to be cancelled, got %v", err)
+	}
+
+	close(release)
+	if projects := <-second; len(projects) != 1 {
+		t.Fatalf("unexpected projects: %+v", projects)
+	}
+	if got := calls.Load(); got != 1 {
+		t.Fatalf("expected a single listing call, got %d", got)
+	}
+}
+
+func TestGetProjectsCacheIsInvalidatedByMutations(t *testing.T) {
+	var listings atomic.Int32
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			listings.Add(1)
+			_, _ = w.Write([]byte(`[]`))
+		case http.MethodPost, http.MethodPatch:
+			_, _ = w.Write([]byte(`{"id": 2}`))
+		default:
+			w.WriteHeader(http.StatusNoContent)
+		}
+	})
+
+	ctx := context.Background()
+	steps := []func() error{
+		func() error { _, err := client.CreateProject(ctx, NewProject{Name: "new"}); return err },
+		func() error { _, err := client.UpdateProject(ctx, 2, UpdateProject{Name: "renamed"}); return err },
+		func() error { return client.DeleteProject(ctx, 2) },
+	}
+
+	if _, err := client.GetProjects(ctx); err != nil {
+		t.Fatal(err)
+	}
+	for i, step := range steps {
+		if err := step(); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := client.GetProjects(ctx); err != nil {
+			t.Fatal(err)
+		}
+		if got, want := listings.Load(), int32(i+2); got != want {
+			t.Fatalf("step %d: expected %d listings, got %d", i, want, got)
+		}
+	}
+}
@@ -2,9 +2,16 @@ package nops
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"net/http"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/function"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/provider"
 	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
@@ -15,7 +22,8 @@ import (
 
 // Ensure the implementation satisfies the expected interfaces.
 var (
-	_ provider.Provider = &nopsIntegrationProvider{}
+	_ provider.Provider              = &nopsIntegrationProvider{}
+	_ provider.ProviderWithFunctions = &nopsIntegrationProvider{}
 )
 
 // New is a helper function to simplify provider server and testing implementation.
@@ -29,8 +37,45 @@ func New(version string) func() provider.Provider {
 
 // nopsIntegrationProviderModel maps provider schema data to a Go type.
 type nopsIntegrationProviderModel struct {
-	ApiKey types.String `tfsdk:"nops_api_key"`
-	Host   types.String `tfsdk:"nops_host"`
+	ApiKey        types.String `tfsdk:"nops_api_key"`
+	ApiKeyCommand types.List   `tfsdk:"api_key_command"`
+	Host          types.String `tfsdk:"nops_host"`
+	Deployment    types.String `tfsdk:"deployment"`
+	ClientID      types.String `tfsdk:"client_id"`
+	ClientSecret  types.String `tfsdk:"client_secret"`
+	TokenURL      types.String `tfsdk:"token_url"`
+	Profile       types.String `tfsdk:"profile"`
+
+	ValidateCredentials   types.Bool   `tfsdk:"validate_credentials"`
+	Caller                types.String `tfsdk:"caller"`
+	StrictProjectReads    types.Bool   `tfsdk:"strict_project_reads"`
+	AdoptExistingProjects types.Bool   `tfsdk:"adopt_existing_projects"`
+
+	HTTP *httpSettingsModel `tfsdk:"http"`
+
+	CABundle           types.String `tfsdk:"ca_bundle"`
+	ClientCert         types.String `tfsdk:"client_cert"`
+	ClientKey          types.String `tfsdk:"client_key"`
+	ProxyURL           types.String `tfsdk:"proxy_url"`
+	InsecureSkipVerify types.Bool   `tfsdk:"insecure_skip_verify"`
+
+	RequestsPerSecond  types.Float64                `tfsdk:"requests_per_second"`
+	Burst              types.Int64                  `tfsdk:"burst"`
+	EndpointRateLimits map[string]rateLimitOverride `tfsdk:"endpoint_rate_limits"`
+}
+
+// httpSettingsModel maps the http block to a Go type.
+type httpSettingsModel struct {
+	MaxRetries     types.Int64  `tfsdk:"max_retries"`
+	RetryWaitMin   types.String `tfsdk:"retry_wait_min"`
+	RetryWaitMax   types.String `tfsdk:"retry_wait_max"`
+	RequestTimeout types.String `tfsdk:"request_timeout"`
+}
+
+// rateLimitOverride maps an endpoint_rate_limits entry to a Go type.
+type rateLimitOverride struct {
+	RequestsPerSecond types.Float64 `tfsdk:"requests_per_second"`
+	Burst             types.Int64   `tfsdk:"burst"`
 }
 
 // nopsIntegrationProvider is the provider implementation.
@@ -39,6 +84,10 @@ type nopsIntegrationProvider struct {
 	// provider is built and ran locally, and "test" when running acceptance
 	// testing.
 	version string
+
+	// wrapTransport, when set, wraps the transport of the nOps client. Tests
+	// use it to record and replay the API traffic.
+	wrapTransport func(http.RoundTripper) http.RoundTripper
 }
 
 // Metadata returns the provider type name.
@@ -57,9 +106,124 @@ func (p *nopsIntegrationProvider) Schema(_ context.Context, _ provider.SchemaReq
 				Sensitive:   true,
 				Description: "nOps API key that will be used for secure communication with the platform APIs, may also be provided with an environment variable NOPS_API_KEY.",
 			},
+			"api_key_command": schema.ListAttribute{
+				Optional:    true,
+				ElementType: types.StringType,
+				Description: "Program and arguments run to obtain the nOps API key, as an alternative to `nops_api_key`, for example `[\"/usr/local/bin/nops-key\", \"--tenant\", \"acme\"]`. " +
+					"The program must print a JSON document such as `{\"api_key\": \"...\", \"expiration\": \"2024-01-01T00:00:00Z\"}` to stdout, `expiration` being optional. " +
+					"It is run again when the key expires or is rejected by the API.",
+			},
 			"nops_host": schema.StringAttribute{
 				Optional:    true,
-				Description: "nOps API URL, may also be provided with an environment variable NOPS_HOST.",
+				Description: "nOps API URL such as `" + HostURL + "`, for stand-ins of the API not covered by `deployment`. Must use https and have no path, plain http is only allowed for localhost. May also be provided with an environment variable NOPS_HOST.",
+			},
+			"deployment": schema.StringAttribute{
+				Optional: true,
+				Description: "nOps deployment to connect to, one of: `" + strings.Join(DeploymentNames(), "`, `") + "`. Defaults to `" + DefaultDeployment + "`. " +
+					"Conflicts with `nops_host`, may also be provided with an environment variable NOPS_DEPLOYMENT.",
+			},
+			"client_id": schema.StringAttribute{
+				Optional:    true,
+				Description: "OAuth client ID exchanged together with `client_secret` for short-lived bearer tokens, as an alternative to `nops_api_key`. May also be provided with an environment variable NOPS_CLIENT_ID.",
+			},
+			"client_secret": schema.StringAttribute{
+				Optional:    true,
+				Sensitive:   true,
+				Description: "OAuth client secret matching `client_id`, may also be provided with an environment variable NOPS_CLIENT_SECRET.",
+			},
+			"token_url": schema.StringAttribute{
+				Optional:    true,
+				Description: fmt.Sprintf("OAuth token endpoint used with `client_id`, defaults to `%s` on the nOps host. May also be provided with an environment variable NOPS_TOKEN_URL.", DefaultTokenPath),
+			},
+			"profile": schema.StringAttribute{
+				Optional: true,
+				Description: "Named profile of the shared credentials file `~/.nops/credentials` to read the API key and host from, may also be provided with an environment variable NOPS_PROFILE. " +
+					"Values set in the configuration take precedence over environment variables, which take precedence over the profile. " +
+					"The `" + DefaultProfile + "` profile is used when none is selected, and the file location may be overridden with an environment variable NOPS_SHARED_CREDENTIALS_FILE.",
+			},
+			"validate_credentials": schema.BoolAttribute{
+				Optional:    true,
+				Description: "Check the credentials against the nOps API when the provider is configured, failing fast with a clear error when they are rejected instead of on the first resource. Defaults to `false`.",
+			},
+			"caller": schema.StringAttribute{
+				Optional:    true,
+				Description: "Free-form name of the pipeline or team running Terraform, sent to nOps as the X-Nops-Caller header to tell apart callers sharing an API key. May also be provided with an environment variable NOPS_CALLER.",
+			},
+			"strict_project_reads": schema.BoolAttribute{
+				Optional:    true,
+				Description: "Fail to refresh an `nops_project` that was deleted outside of Terraform, instead of removing it from state and planning its re-creation. Defaults to `false`.",
+			},
+			"adopt_existing_projects": schema.BoolAttribute{
+				Optional:    true,
+				Description: "Default of the `nops_project` `adopt_existing` attribute, adopting already integrated projects into the state instead of failing, such as when re-running an account vending pipeline after the state was lost. Defaults to `false`.",
+			},
+			"http": schema.SingleNestedAttribute{
+				Optional:    true,
+				Description: "Timeout and retry settings of the nOps API calls.",
+				Attributes: map[string]schema.Attribute{
+					"max_retries": schema.Int64Attribute{
+						Optional:    true,
+						Description: fmt.Sprintf("Maximum number of retries for throttled (429), 5xx or failed API calls. Reads, updates, deletes and the POST notifications nOps treats as upserts are retried, project creation is not. Defaults to %d, set to 0 to disable retries.", DefaultMaxRetries),
+					},
+					"retry_wait_min": schema.StringAttribute{
+						Optional:    true,
+						Description: fmt.Sprintf("Minimum wait between retries as a duration string such as `500ms` or `2s`, doubled on every attempt. Defaults to `%s`.", DefaultRetryWaitMin),
+					},
+					"retry_wait_max": schema.StringAttribute{
+						Optional:    true,
+						Description: fmt.Sprintf("Maximum wait between retries as a duration string, also caps any `Retry-After` sent by the API. Defaults to `%s`.", DefaultRetryWaitMax),
+					},
+					"request_timeout": schema.StringAttribute{
+						Optional:    true,
+						Description: fmt.Sprintf("Time limit for a single API call as a duration string such as `30s`, retries and resource operations are bounded by the resource `timeouts` instead. Defaults to `%s`.", DefaultRequestTimeout),
+					},
+				},
+			},
+			"ca_bundle": schema.StringAttribute{
+				Optional:    true,
+				Description: "PEM encoded CA certificates, or a path to a file holding them, trusted in addition to the system roots when connecting to the nOps API or proxy. May also be provided with an environment variable NOPS_CA_BUNDLE.",
+			},
+			"client_cert": schema.StringAttribute{
+				Optional:    true,
+				Description: "PEM encoded client certificate, or a path to it, presented for mutual TLS. Requires `client_key`.",
+			},
+			"client_key": schema.StringAttribute{
+				Optional:    true,
+				Sensitive:   true,
+				Description: "PEM encoded private key, or a path to it, matching `client_cert`.",
+			},
+			"proxy_url": schema.StringAttribute{
+				Optional:    true,
+				Description: "URL of the proxy used for nOps API calls, such as `https://proxy.example.com:3128`. Defaults to the standard HTTPS_PROXY and NO_PROXY environment variables.",
+			},
+			"insecure_skip_verify": schema.BoolAttribute{
+				Optional:    true,
+				Description: "Skip TLS certificate verification of the nOps API. Only meant for local stand-ins of the API, never enable it against a real nOps deployment.",
+			},
+			"requests_per_second": schema.Float64Attribute{
+				Optional:    true,
+				Description: "Maximum rate of nOps API calls shared by every resource and data source, unlimited when unset or 0. Useful with a high `-parallelism` to avoid being throttled.",
+			},
+			"burst": schema.Int64Attribute{
+				Optional:    true,
+				Description: "Number of calls allowed to exceed `requests_per_second` in a burst. Defaults to `requests_per_second` rounded up.",
+			},
+			"endpoint_rate_limits": schema.MapNestedAttribute{
+				Optional: true,
+				Description: "Request budgets for specific groups of endpoints, replacing the shared budget for them. Keys are one of: " +
+					"`" + strings.Join(EndpointGroups(), "`, `") + "`.",
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"requests_per_second": schema.Float64Attribute{
+							Required:    true,
+							Description: "Maximum rate of calls to the endpoint group, 0 removes any limit.",
+						},
+						"burst": schema.Int64Attribute{
+							Optional:    true,
+							Description: "Number of calls allowed to exceed `requests_per_second` in a burst.",
+						},
+					},
+				},
 			},
 		},
 	}
@@ -67,6 +231,50 @@ func (p *nopsIntegrationProvider) Schema(_ context.Context, _ provider.SchemaReq
 
 // Configure prepares a nopsIntegration API client for data sources and resources.
 func (p *nopsIntegrationProvider) Configure(ctx context.Context, req provider.ConfigureRequest, resp *provider.ConfigureResponse) {
+	// Configuration depending on resources not created yet, such as an API key
+	// stored in a secret of the same run, is resolved in a later round when
+	// Terraform supports deferred actions. This is checked on the raw value as
+	// unknown nested attributes can't be decoded into the model.
+	if !req.Config.Raw.IsFullyKnown() && req.ClientCapabilities.DeferralAllowed {
+		tflog.Debug(ctx, "Deferring nOps provider configuration with unknown values")
+		resp.Deferred = &provider.Deferred{
+			Reason: provider.DeferredReasonProviderConfigUnknown,
+		}
+		return
+	}
+
+	// Unknown nested attributes would otherwise fail to decode with a generic
+	// conversion error, so they are reported on their block first.
+	var httpBlock types.Object
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("http"), &httpBlock)...)
+	if httpBlock.IsUnknown() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("http"),
+			"Unknown nOps HTTP settings",
+			"The provider cannot create the nOps API client as there is an unknown configuration value for the http block. "+
+				"Either target apply the source of the value first or set the value statically in the configuration.",
+		)
+	}
+
+	var endpointRateLimitsBlock types.Map
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("endpoint_rate_limits"), &endpointRateLimitsBlock)...)
+	unknownRateLimit := endpointRateLimitsBlock.IsUnknown()
+	for _, override := range endpointRateLimitsBlock.Elements() {
+		unknownRateLimit = unknownRateLimit || override.IsUnknown()
+	}
+	if unknownRateLimit {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("endpoint_rate_limits"),
+			"Unknown nOps endpoint rate limits",
+			"The provider cannot create the nOps API client as there is an unknown configuration value for the endpoint_rate_limits block. "+
+				"Either target apply the source of the value first or set the value statically in the configuration.",
+		)
+	}
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	// Retrieve provider data from configuration
 	var config nopsIntegrationProviderModel
 	diags := req.Config.Get(ctx, &config)
@@ -87,6 +295,24 @@ func (p *nopsIntegrationProvider) Configure(ctx context.Context, req provider.Co
 		)
 	}
 
+	if config.ApiKeyCommand.IsUnknown() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("api_key_command"),
+			"Unknown nOps API key command",
+			"The provider cannot create the nOps API client as there is an unknown configuration value for the nOps API key command. "+
+				"Either target apply the source of the value first or set the value statically in the configuration.",
+		)
+	}
+
+	if config.ClientID.IsUnknown() || config.ClientSecret.IsUnknown() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("client_id"),
+			"Unknown nOps OAuth client credentials",
+			"The provider cannot create the nOps API client as there is an unknown configuration value for the nOps OAuth client credentials. "+
+				"Either target apply the source of the value first, set the value statically in the configuration, or use the NOPS_CLIENT_ID and NOPS_CLIENT_SECRET environment variables.",
+		)
+	}
+
 	if config.Host.IsUnknown() {
 		resp.Diagnostics.AddAttributeError(
 			path.Root("host"),
@@ -96,6 +322,87 @@ func (p *nopsIntegrationProvider) Configure(ctx context.Context, req provider.Co
 		)
 	}
 
+	if config.Deployment.IsUnknown() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("deployment"),
+			"Unknown nOps deployment",
+			"The provider cannot create the nOps API client as there is an unknown configuration value for the nOps deployment. "+
+				"Either target apply the source of the value first, set the value statically in the configuration, or use the NOPS_DEPLOYMENT environment variable.",
+		)
+	}
+
+	if config.Profile.IsUnknown() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("profile"),
+			"Unknown nOps credentials profile",
+			"The provider cannot create the nOps API client as there is an unknown configuration value for the nOps credentials profile. "+
+				"Either target apply the source of the value first, set the value statically in the configuration, or use the NOPS_PROFILE environment variable.",
+		)
+	}
+
+	if config.CABundle.IsUnknown() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("ca_bundle"),
+			"Unknown nOps CA bundle",
+			"The provider cannot create the nOps API client as there is an unknown configuration value for the nOps CA bundle. "+
+				"Either target apply the source of the value first, set the value statically in the configuration, or use the NOPS_CA_BUNDLE environment variable.",
+		)
+	}
+
+	if config.ClientCert.IsUnknown() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("client_cert"),
+			"Unknown nOps client certificate",
+			"The provider cannot create the nOps API client as there is an unknown configuration value for the nOps client certificate. "+
+				"Either target apply the source of the value first or set the value statically in the configuration.",
+		)
+	}
+
+	if config.ClientKey.IsUnknown() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("client_key"),
+			"Unknown nOps client key",
+			"The provider cannot create the nOps API client as there is an unknown configuration value for the nOps client key. "+
+				"Either target apply the source of the value first or set the value statically in the configuration.",
+		)
+	}
+
+	if config.ProxyURL.IsUnknown() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("proxy_url"),
+			"Unknown nOps proxy URL",
+			"The provider cannot create the nOps API client as there is an unknown configuration value for the nOps proxy URL. "+
+				"Either target apply the source of the value first, set the value statically in the configuration, or use the HTTPS_PROXY environment variable.",
+		)
+	}
+
+	if config.InsecureSkipVerify.IsUnknown() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("insecure_skip_verify"),
+			"Unknown nOps TLS verification setting",
+			"The provider cannot create the nOps API client as there is an unknown configuration value for skipping the TLS verification of the nOps API. "+
+				"Either target apply the source of the value first or set the value statically in the configuration.",
+		)
+	}
+
+	if config.RequestsPerSecond.IsUnknown() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("requests_per_second"),
+			"Unknown nOps API rate limit",
+			"The provider cannot create the nOps API client as there is an unknown configuration value for the nOps API rate limit. "+
+				"Either target apply the source of the value first or set the value statically in the configuration.",
+		)
+	}
+
+	if config.Burst.IsUnknown() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("burst"),
+			"Unknown nOps API rate limit burst",
+			"The provider cannot create the nOps API client as there is an unknown configuration value for the nOps API rate limit burst. "+
+				"Either target apply the source of the value first or set the value statically in the configuration.",
+		)
+	}
+
 	if resp.Diagnostics.HasError() {
 		return
 	}
@@ -105,21 +412,114 @@ func (p *nopsIntegrationProvider) Configure(ctx context.Context, req provider.Co
 
 	apiKey := os.Getenv("NOPS_API_KEY")
 	host := os.Getenv("NOPS_HOST")
+	deployment := os.Getenv("NOPS_DEPLOYMENT")
 
 	if !config.ApiKey.IsNull() {
 		apiKey = config.ApiKey.ValueString()
 	}
 
+	// A deployment in the configuration takes precedence over NOPS_HOST.
+	if !config.Deployment.IsNull() {
+		deployment = config.Deployment.ValueString()
+		host = ""
+	}
+
 	if !config.Host.IsNull() {
 		host = config.Host.ValueString()
 	}
 
-	if apiKey == "" {
+	if !config.Host.IsNull() && !config.Deployment.IsNull() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("deployment"),
+			"Conflicting nOps hosts",
+			"Both nops_host and deployment are configured, configure only one of them.",
+		)
+	}
+
+	if host == "" && deployment != "" {
+		d, ok := LookupDeployment(deployment)
+		if !ok {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("deployment"),
+				"Unknown nOps deployment",
+				fmt.Sprintf("The deployment %q is not supported, expected one of: %s.", deployment, strings.Join(DeploymentNames(), ", ")),
+			)
+		}
+		host = d.HostURL
+	}
+
+	auth := AuthStruct{
+		ClientID:     os.Getenv("NOPS_CLIENT_ID"),
+		ClientSecret: os.Getenv("NOPS_CLIENT_SECRET"),
+		TokenURL:     os.Getenv("NOPS_TOKEN_URL"),
+	}
+	if !config.ClientID.IsNull() {
+		auth.ClientID = config.ClientID.ValueString()
+	}
+	if !config.ClientSecret.IsNull() {
+		auth.ClientSecret = config.ClientSecret.ValueString()
+	}
+	if !config.TokenURL.IsNull() {
+		auth.TokenURL = config.TokenURL.ValueString()
+	}
+	if !config.ApiKeyCommand.IsNull() {
+		resp.Diagnostics.Append(config.ApiKeyCommand.ElementsAs(ctx, &auth.ApiKeyCommand, false)...)
+		if len(auth.ApiKeyCommand) == 0 || auth.ApiKeyCommand[0] == "" {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("api_key_command"),
+				"Invalid nOps API key command",
+				"The api_key_command must start with the program to run.",
+			)
+		}
+	}
+
+	// The shared credentials profile only fills in values that neither the
+	// configuration nor the environment set, so it isn't read at all when
+	// they set everything and no profile is selected.
+	profileName := os.Getenv("NOPS_PROFILE")
+	if !config.Profile.IsNull() {
+		profileName = config.Profile.ValueString()
+	}
+	hasCredentials := apiKey != "" || auth.ClientID != "" || auth.usesApiKeyCommand()
+	if profileName != "" || !hasCredentials || host == "" {
+		if profile := loadProfile(profileName, &resp.Diagnostics); profile != nil {
+			if !hasCredentials {
+				apiKey = profile.ApiKey
+			}
+			if host == "" {
+				host = profile.Host
+			}
+		}
+	}
+
+	// OAuth client credentials and the API key command take precedence over an
+	// API key set in the environment.
+	switch {
+	case auth.ClientID != "" && !config.ApiKey.IsNull():
+		resp.Diagnostics.AddAttributeError(
+			path.Root("client_id"),
+			"Conflicting nOps credentials",
+			"Both nops_api_key and OAuth client credentials are configured, configure only one authentication mode.",
+		)
+	case auth.usesApiKeyCommand() && (auth.ClientID != "" || !config.ApiKey.IsNull()):
+		resp.Diagnostics.AddAttributeError(
+			path.Root("api_key_command"),
+			"Conflicting nOps credentials",
+			"The api_key_command cannot be combined with nops_api_key or OAuth client credentials, configure only one authentication mode.",
+		)
+	case auth.ClientID != "" && auth.ClientSecret == "":
+		resp.Diagnostics.AddAttributeError(
+			path.Root("client_secret"),
+			"Missing nOps OAuth client secret",
+			"A client_secret must be provided together with client_id, set it in the configuration or use the NOPS_CLIENT_SECRET environment variable.",
+		)
+	case auth.ClientID == "" && apiKey == "" && !auth.usesApiKeyCommand():
 		resp.Diagnostics.AddAttributeError(
 			path.Root("apiKey"),
 			"Missing nOps API Key",
 			"The provider cannot create the nOps API client as there is a missing or empty value for the nOps API key. "+
-				"Set the API key value in the configuration or use the NOPS_API_KEY environment variable. "+
+				"Set the API key value in the configuration, use the NOPS_API_KEY environment variable or a profile of the shared credentials file, "+
+				"or configure OAuth client credentials with client_id and client_secret, or an api_key_command. "+
 				"If either is already set, ensure the value is not empty.",
 		)
 	}
@@ -128,12 +528,134 @@ func (p *nopsIntegrationProvider) Configure(ctx context.Context, req provider.Co
 		host = HostURL
 	}
 
+	if normalized, err := normalizeHost(host); err != nil {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("nops_host"),
+			"Invalid nOps API host",
+			fmt.Sprintf("The nOps API host %q is not valid: %s.", host, err),
+		)
+	} else {
+		host = normalized
+	}
+
+	if !isKnownHost(host) && (apiKey != "" || auth.usesApiKeyCommand()) && !resp.Diagnostics.HasError() {
+		resp.Diagnostics.AddAttributeWarning(
+			path.Root("nops_host"),
+			"Custom nOps API host",
+			fmt.Sprintf("The nOps API key will be sent to %s, which is not a known nOps deployment. "+
+				"Use the deployment attribute instead unless this host is a trusted stand-in of the API.", host),
+		)
+	}
+
+	httpSettings := httpSettingsModel{}
+	if config.HTTP != nil {
+		httpSettings = *config.HTTP
+	}
+	httpPath := path.Root("http").AtName
+
+	maxRetries := DefaultMaxRetries
+	if !httpSettings.MaxRetries.IsNull() && !httpSettings.MaxRetries.IsUnknown() {
+		maxRetries = int(httpSettings.MaxRetries.ValueInt64())
+		if maxRetries < 0 {
+			resp.Diagnostics.AddAttributeError(
+				httpPath("max_retries"),
+				"Invalid nOps max retries",
+				"The max_retries value must be zero or greater.",
+			)
+		}
+	}
+
+	retryWaitMin := parseDurationAttribute(httpSettings.RetryWaitMin, httpPath("retry_wait_min"), DefaultRetryWaitMin, &resp.Diagnostics)
+	retryWaitMax := parseDurationAttribute(httpSettings.RetryWaitMax, httpPath("retry_wait_max"), DefaultRetryWaitMax, &resp.Diagnostics)
+	if retryWaitMin > retryWaitMax {
+		resp.Diagnostics.AddAttributeError(
+			httpPath("retry_wait_min"),
+			"Invalid nOps retry wait bounds",
+			fmt.Sprintf("The retry_wait_min value (%s) must not be greater than retry_wait_max (%s).", retryWaitMin, retryWaitMax),
+		)
+	}
+
+	requestTimeout := parseDurationAttribute(httpSettings.RequestTimeout, httpPath("request_timeout"), DefaultRequestTimeout, &resp.Diagnostics)
+
+	transportConfig := TransportConfig{
+		CABundle:           os.Getenv("NOPS_CA_BUNDLE"),
+		ClientCert:         config.ClientCert.ValueString(),
+		ClientKey:          config.ClientKey.ValueString(),
+		ProxyURL:           config.ProxyURL.ValueString(),
+		InsecureSkipVerify: config.InsecureSkipVerify.ValueBool(),
+	}
+	if !config.CABundle.IsNull() {
+		transportConfig.CABundle = config.CABundle.ValueString()
+	}
+
+	if transportConfig.ClientCert != "" && transportConfig.ClientKey == "" {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("client_key"),
+			"Missing nOps client key",
+			"A client_key must be provided together with client_cert for mutual TLS.",
+		)
+	}
+	if transportConfig.ClientKey != "" && transportConfig.ClientCert == "" {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("client_cert"),
+			"Missing nOps client certificate",
+			"A client_cert must be provided together with client_key for mutual TLS.",
+		)
+	}
+
+	globalRateLimit := RateLimit{
+		RequestsPerSecond: config.RequestsPerSecond.ValueFloat64(),
+		Burst:             int(config.Burst.ValueInt64()),
+	}
+	if globalRateLimit.RequestsPerSecond < 0 {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("requests_per_second"),
+			"Invalid nOps rate limit",
+			"The requests_per_second value must be zero or greater.",
+		)
+	}
+
+	endpointRateLimits := map[string]RateLimit{}
+	for group, override := range config.EndpointRateLimits {
+		endpointRateLimits[group] = RateLimit{
+			RequestsPerSecond: override.RequestsPerSecond.ValueFloat64(),
+			Burst:             int(override.Burst.ValueInt64()),
+		}
+		if !isEndpointGroup(group) {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("endpoint_rate_limits").AtMapKey(group),
+				"Unknown nOps endpoint group",
+				fmt.Sprintf("The endpoint group %q is not supported, expected one of: %s.", group, strings.Join(EndpointGroups(), ", ")),
+			)
+		}
+	}
+
+	if transportConfig.InsecureSkipVerify {
+		resp.Diagnostics.AddAttributeWarning(
+			path.Root("insecure_skip_verify"),
+			"TLS verification disabled",
+			"The nOps API certificate will not be verified, this must only be used against local stand-ins of the API.",
+		)
+	}
+
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
+	transport, err := NewTransport(transportConfig)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to Configure nOps API Transport",
+			"An error occurred when applying the TLS and proxy settings of the nOps API client.\n\n"+
+				"nOps Client Error: "+err.Error(),
+		)
+		return
+	}
+
 	ctx = tflog.SetField(ctx, "nops_host", host)
 	ctx = tflog.SetField(ctx, "nops_api_key", apiKey)
+	ctx = tflog.SetField(ctx, "nops_client_id", auth.ClientID)
+	ctx = tflog.SetField(ctx, "nops_profile", profileName)
 	ctx = tflog.MaskFieldValuesWithFieldKeys(ctx, "nops_api_key")
 	tflog.Debug(ctx, "Creating nops client")
 
@@ -149,6 +671,71 @@ func (p *nopsIntegrationProvider) Configure(ctx context.Context, req provider.Co
 		return
 	}
 
+	if auth.refreshable() {
+		client.Auth = auth
+	}
+	client.HTTPClient.Transport = transport
+	if p.wrapTransport != nil {
+		client.HTTPClient.Transport = p.wrapTransport(transport)
+	}
+	client.HTTPClient.Timeout = requestTimeout
+	client.UserAgent = UserAgent(p.version, req.TerraformVersion)
+	client.Caller = os.Getenv("NOPS_CALLER")
+	if !config.Caller.IsNull() {
+		client.Caller = config.Caller.ValueString()
+	}
+	client.MaxRetries = maxRetries
+	client.RetryWaitMin = retryWaitMin
+	client.RetryWaitMax = retryWaitMax
+	client.StrictProjectReads = config.StrictProjectReads.ValueBool()
+	client.AdoptExistingProjects = config.AdoptExistingProjects.ValueBool()
+
+	if err := client.SetRateLimits(globalRateLimit, endpointRateLimits); err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to Configure nOps API Rate Limits",
+			"nOps Client Error: "+err.Error(),
+		)
+		return
+	}
+
+	// Run the API key command upfront so a broken command is reported once
+	// here rather than by every resource.
+	if auth.usesApiKeyCommand() {
+		if _, err := client.commandApiKey(ctx); err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("api_key_command"),
+				"Unable to Obtain nOps API Key",
+				"The api_key_command failed to provide an nOps API key. "+
+					"Ensure the program can be run by Terraform and prints a JSON document with an api_key to stdout.\n\n"+
+					"Error: "+err.Error(),
+			)
+			return
+		}
+	}
+
+	if config.ValidateCredentials.ValueBool() {
+		identity, err := client.GetCallerIdentity(ctx)
+		if IsUnauthorized(err) {
+			resp.Diagnostics.AddError(
+				"Invalid nOps Credentials",
+				fmt.Sprintf("The nOps API at %s rejected the configured credentials. "+
+					"Ensure the API key or OAuth client credentials are valid, not revoked, and belong to the expected nOps client.\n\n", host)+
+					"nOps Client Error: "+err.Error(),
+			)
+			return
+		}
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Unable to Validate nOps Credentials",
+				"An error occurred when resolving the identity of the configured nOps credentials.\n\n"+
+					"nOps Client Error: "+err.Error(),
+			)
+			return
+		}
+		client.Identity = identity
+		tflog.Info(ctx, "Validated nOps credentials", map[string]any{"client_id": identity.ClientID, "key_name": identity.Name})
+	}
+
 	// Make the nOps client available during DataSource and Resource
 	// type Configure methods.
 	resp.DataSourceData = client
@@ -158,10 +745,69 @@ func (p *nopsIntegrationProvider) Configure(ctx context.Context, req provider.Co
 
 }
 
+// parseDurationAttribute parses an optional duration string attribute, falling back to def when unset.
+func parseDurationAttribute(value types.String, attrPath path.Path, def time.Duration, diags *diag.Diagnostics) time.Duration {
+	if value.IsNull() || value.IsUnknown() {
+		return def
+	}
+
+	d, err := time.ParseDuration(value.ValueString())
+	if err != nil || d < 0 {
+		diags.AddAttributeError(
+			attrPath,
+			"Invalid duration",
+			fmt.Sprintf("The value %q is not a valid non-negative duration, use a value such as \"500ms\" or \"2s\".", value.ValueString()),
+		)
+		return def
+	}
+
+	return d
+}
+
+// loadProfile reads the named profile from the shared credentials file. Without
+// an explicitly selected profile, the default one is used if it can be read.
+func loadProfile(name string, diags *diag.Diagnostics) *CredentialsProfile {
+	explicit := name != ""
+	if !explicit {
+		name = DefaultProfile
+	}
+
+	file, err := SharedCredentialsFile()
+	if err == nil {
+		var profile *CredentialsProfile
+		profile, err = LoadCredentialsProfile(file, name)
+		if err == nil {
+			return profile
+		}
+	}
+
+	if !explicit && (isCredentialsFileMissing(err) || errors.Is(err, errProfileNotFound)) {
+		return nil
+	}
+	// The default profile is optional, a broken file or home directory must
+	// not fail configurations that don't rely on it.
+	if !explicit {
+		diags.AddAttributeWarning(
+			path.Root("profile"),
+			"Unable to Read nOps Credentials Profile",
+			fmt.Sprintf("The %q profile of the shared credentials file was ignored as it cannot be read.\n\nError: %s", name, err),
+		)
+		return nil
+	}
+	diags.AddAttributeError(
+		path.Root("profile"),
+		"Unable to Read nOps Credentials Profile",
+		fmt.Sprintf("The provider cannot read the %q profile of the shared credentials file.\n\nError: %s", name, err),
+	)
+	return nil
+}
+
 // DataSources defines the data sources implemented in the provider.
 func (p *nopsIntegrationProvider) DataSources(_ context.Context) []func() datasource.DataSource {
 	return []func() datasource.DataSource{
 		NewProjectsDataSource,
+		NewCallerIdentityDataSource,
+		NewIAMPolicyDocumentDataSource,
 	}
 }
 
@@ -174,3 +820,12 @@ func (p *nopsIntegrationProvider) Resources(_ context.Context) []func() resource
 		containerCostResource,
 	}
 }
+
+// Functions defines the provider-defined functions implemented in the provider.
+func (p *nopsIntegrationProvider) Functions(_ context.Context) []func() function.Function {
+	return []func() function.Function{
+		NewSystemBucketNameFunction,
+		NewIsPayerAccountFunction,
+		NewIntegrationTrustPolicyFunction,
+	}
+}
@@ -1,11 +1,22 @@
 package nops
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"path/filepath"
+	"testing"
 
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/provider"
 	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
 	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+
+	"terraform-provider-nops/nops/internal/fakenops"
+	"terraform-provider-nops/nops/internal/vcr"
 )
 
 var (
@@ -16,7 +27,7 @@ var (
 	testAccProtoV6ProviderFactories = map[string]func() (tfprotov6.ProviderServer, error){
 		"nops": providerserver.NewProtocol6WithError(New("test")()),
 	}
-	nops_api_key = os.Getenv("NOPS_API_KEY")
+	nops_api_key = testAccAPIKey()
 	// providerConfig is a shared configuration to combine with the actual
 	// test configuration so the nOps client is properly configured.
 	// It is also possible to use the environment variables instead,
@@ -27,3 +38,267 @@ provider "nops" {
 }`, nops_api_key,
 	)
 )
+
+// testAccAPIKey returns the API key used by acceptance tests, replayed
+// cassettes don't need a real one.
+func testAccAPIKey() string {
+	apiKey := os.Getenv("NOPS_API_KEY")
+	if mode, _ := vcr.ModeFromEnv(); mode == vcr.ModeReplay && apiKey == "" {
+		return "replayed-api-key"
+	}
+	return apiKey
+}
+
+// testAccProtoV6ProviderFactoriesWithVCR returns provider factories whose API
+// traffic is recorded to, or replayed from, testdata/cassettes/<test name>.json
+// when NOPS_VCR_MODE is set to record or replay.
+func testAccProtoV6ProviderFactoriesWithVCR(t *testing.T) map[string]func() (tfprotov6.ProviderServer, error) {
+	t.Helper()
+
+	mode, err := vcr.ModeFromEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mode == vcr.ModeDisabled {
+		return testAccProtoV6ProviderFactories
+	}
+
+	cassette := filepath.Join("testdata", "cassettes", t.Name()+".json")
+	recorder, err := vcr.New(mode, cassette, nil)
+	if vcr.IsCassetteNotFound(err) {
+		// Skipping would let a replay run pass without exercising anything.
+		t.Fatalf("no cassette recorded for %s, run the test with %s=record against a live tenant first", t.Name(), vcr.EnvMode)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := recorder.Stop(); err != nil {
+			t.Errorf("saving cassette: %s", err)
+		}
+	})
+
+	return map[string]func() (tfprotov6.ProviderServer, error){
+		"nops": providerserver.NewProtocol6WithError(&nopsIntegrationProvider{
+			version:       "test",
+			wrapTransport: recorder.Wrap,
+		}),
+	}
+}
+
+// newTestAccFakeServer starts a fake nOps API that lives for the duration of the test.
+func newTestAccFakeServer(t *testing.T) *fakenops.Server {
+	t.Helper()
+
+	server := fakenops.NewServer()
+	t.Cleanup(server.Close)
+	return server
+}
+
+// fakeProviderConfig points the provider at a fake nOps API, with short
+// retry waits so injected faults don't slow the tests down.
+func fakeProviderConfig(server *fakenops.Server) string {
+	return fmt.Sprintf(`
+provider "nops" {
+  nops_host    = %q
+  nops_api_key = %q
+
+  http = {
+    retry_wait_min = "1ms"
+    retry_wait_max = "10ms"
+  }
+}`, server.URL, server.APIKey)
+}
+
+// fakeStrictProviderConfig is fakeProviderConfig with strict_project_reads set.
+func fakeStrictProviderConfig(server *fakenops.Server) string {
+	return fmt.Sprintf(`
+provider "nops" {
+  nops_host            = %q
+  nops_api_key         = %q
+  strict_project_reads = true
+}`, server.URL, server.APIKey)
+}
+
+// newTestProviderConfig builds a provider configuration with every attribute
+// null, except the ones returned by set for the attribute types of the schema.
+func newTestProviderConfig(t *testing.T, set func(attrTypes map[string]tftypes.Type) map[string]tftypes.Value) tfsdk.Config {
+	t.Helper()
+
+	ctx := context.Background()
+	schemaResp := &provider.SchemaResponse{}
+	New("test")().Schema(ctx, provider.SchemaRequest{}, schemaResp)
+	configType, ok := schemaResp.Schema.Type().TerraformType(ctx).(tftypes.Object)
+	if !ok {
+		t.Fatal("expected the provider schema to be an object")
+	}
+
+	values := map[string]tftypes.Value{}
+	for name, attrType := range configType.AttributeTypes {
+		values[name] = tftypes.NewValue(attrType, nil)
+	}
+	for name, value := range set(configType.AttributeTypes) {
+		values[name] = value
+	}
+	return tfsdk.Config{
+		Schema: schemaResp.Schema,
+		Raw:    tftypes.NewValue(configType, values),
+	}
+}
+
+func TestProviderConfigureDefersUnknownConfig(t *testing.T) {
+	ctx := context.Background()
+	p := New("test")()
+
+	config := newTestProviderConfig(t, func(attrTypes map[string]tftypes.Type) map[string]tftypes.Value {
+		return map[string]tftypes.Value{
+			"nops_api_key": tftypes.NewValue(tftypes.String, tftypes.UnknownValue),
+			// Unknown nested attributes can't be decoded into the provider model.
+			"http":                 tftypes.NewValue(attrTypes["http"], tftypes.UnknownValue),
+			"endpoint_rate_limits": tftypes.NewValue(attrTypes["endpoint_rate_limits"], tftypes.UnknownValue),
+		}
+	})
+
+	deferred := &provider.ConfigureResponse{}
+	p.Configure(ctx, provider.ConfigureRequest{
+		Config:             config,
+		ClientCapabilities: provider.ConfigureProviderClientCapabilities{DeferralAllowed: true},
+	}, deferred)
+	if deferred.Diagnostics.HasError() || deferred.Deferred == nil || deferred.Deferred.Reason != provider.DeferredReasonProviderConfigUnknown {
+		t.Fatalf("expected the configuration to be deferred, got %+v", deferred)
+	}
+
+	// Terraform versions without deferred actions keep getting an error.
+	failed := &provider.ConfigureResponse{}
+	p.Configure(ctx, provider.ConfigureRequest{Config: config}, failed)
+	if failed.Deferred != nil {
+		t.Fatalf("expected an error without deferral support, got %+v", failed)
+	}
+	// The unknown blocks are named instead of failing to decode the model.
+	errs := failed.Diagnostics.Errors()
+	if len(errs) != 2 {
+		t.Fatalf("expected an error for each unknown block, got %v", failed.Diagnostics)
+	}
+	for i, expected := range []struct {
+		path    path.Path
+		summary string
+	}{
+		{path.Root("http"), "Unknown nOps HTTP settings"},
+		{path.Root("endpoint_rate_limits"), "Unknown nOps endpoint rate limits"},
+	} {
+		withPath, ok := errs[i].(diag.DiagnosticWithPath)
+		if !ok || !withPath.Path().Equal(expected.path) || errs[i].Summary() != expected.summary {
+			t.Fatalf("expected a %q error on %s, got %v", expected.summary, expected.path, errs[i])
+		}
+	}
+}
+
+func TestProviderConfigureUnknownTransportConfig(t *testing.T) {
+	for name, summary := range map[string]string{
+		"ca_bundle":            "Unknown nOps CA bundle",
+		"client_cert":          "Unknown nOps client certificate",
+		"client_key":           "Unknown nOps client key",
+		"proxy_url":            "Unknown nOps proxy URL",
+		"insecure_skip_verify": "Unknown nOps TLS verification setting",
+		"requests_per_second":  "Unknown nOps API rate limit",
+		"burst":                "Unknown nOps API rate limit burst",
+	} {
+		t.Run(name, func(t *testing.T) {
+			config := newTestProviderConfig(t, func(attrTypes map[string]tftypes.Type) map[string]tftypes.Value {
+				return map[string]tftypes.Value{
+					"nops_api_key": tftypes.NewValue(tftypes.String, "key"),
+					name:           tftypes.NewValue(attrTypes[name], tftypes.UnknownValue),
+				}
+			})
+
+			resp := &provider.ConfigureResponse{}
+			New("test")().Configure(context.Background(), provider.ConfigureRequest{Config: config}, resp)
+			errs := resp.Diagnostics.Errors()
+			if len(errs) != 1 || errs[0].Summary() != summary {
+				t.Fatalf("expected a %q error, got %v", summary, resp.Diagnostics)
+			}
+			withPath, ok := errs[0].(diag.DiagnosticWithPath)
+			if !ok || !withPath.Path().Equal(path.Root(name)) {
+				t.Fatalf("expected the error to be reported on %s, got %v", name, errs[0])
+			}
+		})
+	}
+}
+
+func TestProviderConfigureCredentialsPrecedence(t *testing.T) {
+	credentials := filepath.Join(t.TempDir(), "credentials")
+	if err := os.WriteFile(credentials, []byte("[default]\napi_key = profile-key\nhost = https://profile.example.com\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	configure := func(t *testing.T, set map[string]string) (*Client, diag.Diagnostics) {
+		t.Helper()
+		config := newTestProviderConfig(t, func(map[string]tftypes.Type) map[string]tftypes.Value {
+			values := map[string]tftypes.Value{}
+			for name, value := range set {
+				values[name] = tftypes.NewValue(tftypes.String, value)
+			}
+			return values
+		})
+		resp := &provider.ConfigureResponse{}
+		New("test")().Configure(context.Background(), provider.ConfigureRequest{Config: config}, resp)
+		client, _ := resp.ResourceData.(*Client)
+		return client, resp.Diagnostics
+	}
+	expect := func(t *testing.T, client *Client, diags diag.Diagnostics, apiKey, host string) {
+		t.Helper()
+		if diags.HasError() || client == nil {
+			t.Fatalf("unexpected diagnostics: %v", diags)
+		}
+		if client.Auth.ApiKey != apiKey || client.HostURL != host {
+			t.Fatalf("expected %s at %s, got %s at %s", apiKey, host, client.Auth.ApiKey, client.HostURL)
+		}
+	}
+
+	for _, name := range []string{"NOPS_API_KEY", "NOPS_HOST", "NOPS_PROFILE", "NOPS_DEPLOYMENT", "NOPS_CLIENT_ID", "NOPS_CLIENT_SECRET"} {
+		t.Setenv(name, "")
+	}
+	t.Setenv("NOPS_SHARED_CREDENTIALS_FILE", credentials)
+
+	t.Run("profile", func(t *testing.T) {
+		client, diags := configure(t, nil)
+		expect(t, client, diags, "profile-key", "https://profile.example.com")
+	})
+
+	t.Run("environment over profile", func(t *testing.T) {
+		t.Setenv("NOPS_API_KEY", "env-key")
+		t.Setenv("NOPS_HOST", "https://env.example.com")
+		client, diags := configure(t, nil)
+		expect(t, client, diags, "env-key", "https://env.example.com")
+	})
+
+	t.Run("configuration over environment", func(t *testing.T) {
+		t.Setenv("NOPS_API_KEY", "env-key")
+		t.Setenv("NOPS_HOST", "https://env.example.com")
+		client, diags := configure(t, map[string]string{"nops_api_key": "config-key", "nops_host": "https://config.example.com"})
+		expect(t, client, diags, "config-key", "https://config.example.com")
+	})
+
+	t.Run("environment without a home directory", func(t *testing.T) {
+		t.Setenv("NOPS_SHARED_CREDENTIALS_FILE", "")
+		t.Setenv("HOME", "")
+		t.Setenv("NOPS_API_KEY", "env-key")
+		client, diags := configure(t, nil)
+		expect(t, client, diags, "env-key", HostURL)
+	})
+
+	t.Run("malformed default profile", func(t *testing.T) {
+		malformed := filepath.Join(t.TempDir(), "credentials")
+		if err := os.WriteFile(malformed, []byte("[default\n"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		t.Setenv("NOPS_SHARED_CREDENTIALS_FILE", malformed)
+		t.Setenv("NOPS_API_KEY", "env-key")
+		client, diags := configure(t, nil)
+		expect(t, client, diags, "env-key", HostURL)
+
+		if _, diags := configure(t, map[string]string{"profile": "default"}); !diags.HasError() {
+			t.Fatal("expected an error for an explicitly selected profile that cannot be read")
+		}
+	})
+}
@@ -0,0 +1,117 @@
+package nops
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"golang.org/x/time/rate"
+)
+
+// Endpoint groups that can be given their own request budget.
+const (
+	// EndpointGroupAdmin covers the /c/admin/ project and container cost bucket APIs.
+	EndpointGroupAdmin string = "admin"
+	// EndpointGroupIntegration covers the /c/aws/ integration notifications.
+	EndpointGroupIntegration string = "integration"
+	// EndpointGroupKarpenterManager covers the compute copilot karpenter_manager service.
+	EndpointGroupKarpenterManager string = "karpenter_manager"
+)
+
+var endpointGroupPrefixes = map[string]string{
+	"/c/admin/":               EndpointGroupAdmin,
+	"/c/aws/":                 EndpointGroupIntegration,
+	"/svc/karpenter_manager/": EndpointGroupKarpenterManager,
+}
+
+// EndpointGroups returns the names of every endpoint group, sorted.
+func EndpointGroups() []string {
+	groups := make([]string, 0, len(endpointGroupPrefixes))
+	for _, group := range endpointGroupPrefixes {
+		groups = append(groups, group)
+	}
+	sort.Strings(groups)
+	return groups
+}
+
+// endpointGroup returns the group an API path belongs to, empty when it has none.
+func endpointGroup(path string) string {
+	for prefix, group := range endpointGroupPrefixes {
+		if strings.HasPrefix(path, prefix) {
+			return group
+		}
+	}
+	return ""
+}
+
+// RateLimit - token bucket budget for API calls, a zero RequestsPerSecond means unlimited.
+type RateLimit struct {
+	RequestsPerSecond float64
+	// Burst defaults to the per second rate, rounded up, when zero.
+	Burst int
+}
+
+func (l RateLimit) limiter() *rate.Limiter {
+	if l.RequestsPerSecond <= 0 {
+		return nil
+	}
+	burst := l.Burst
+	if burst <= 0 {
+		burst = int(math.Ceil(l.RequestsPerSecond))
+	}
+	return rate.NewLimiter(rate.Limit(l.RequestsPerSecond), burst)
+}
+
+// rateLimiter holds the shared budget and the per endpoint group overrides.
+type rateLimiter struct {
+	global *rate.Limiter
+	groups map[string]*rate.Limiter
+}
+
+// SetRateLimits - limits the client to the global budget, except for the
+// endpoint groups with an override which get a budget of their own.
+func (c *Client) SetRateLimits(global RateLimit, overrides map[string]RateLimit) error {
+	limiter := &rateLimiter{
+		global: global.limiter(),
+		groups: map[string]*rate.Limiter{},
+	}
+
+	for group, limit := range overrides {
+		if !isEndpointGroup(group) {
+			return fmt.Errorf("unknown endpoint group %q, expected one of: %s", group, strings.Join(EndpointGroups(), ", "))
+		}
+		limiter.groups[group] = limit.limiter()
+	}
+
+	c.rateLimiter = limiter
+	return nil
+}
+
+// waitForRateLimit blocks until the budget of the endpoint allows another
+// call, or returns the context error when it's cancelled first.
+func (c *Client) waitForRateLimit(ctx context.Context, path string) error {
+	if c.rateLimiter == nil {
+		return nil
+	}
+
+	limiter := c.rateLimiter.global
+	if override, ok := c.rateLimiter.groups[endpointGroup(path)]; ok {
+		limiter = override
+	}
+	if limiter == nil {
+		return nil
+	}
+
+	return limiter.Wait(ctx)
+}
+
+func isEndpointGroup(name string) bool {
+	for _, group := range endpointGroupPrefixes {
+		if group == name {
+			return true
+		}
+	}
+	return false
+}
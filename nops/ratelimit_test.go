@@ -0,0 +1,46 @@
+package nops
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestRateLimitRespectsContextAndOverrides(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[]`))
+	})
+
+	err := client.SetRateLimits(RateLimit{RequestsPerSecond: 0.5, Burst: 1}, map[string]RateLimit{
+		EndpointGroupKarpenterManager: {RequestsPerSecond: 0},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.ProjectsCacheTTL = 0
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	if _, err := client.GetProjects(ctx); err != nil {
+		t.Fatalf("first call should use the burst: %s", err)
+	}
+	if _, err := client.GetProjects(ctx); err == nil {
+		t.Fatal("expected the second call to be stopped by the context deadline")
+	}
+
+	// The karpenter_manager group has its own, unlimited, budget.
+	for i := 0; i < 3; i++ {
+		if err := client.DeleteComputeCopilotOnboarding(context.Background(), "us-east-1", "1"); err != nil {
+			t.Fatalf("override call %d: %s", i, err)
+		}
+	}
+}
+
+func TestSetRateLimitsRejectsUnknownGroups(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {})
+	if err := client.SetRateLimits(RateLimit{}, map[string]RateLimit{"projects": {RequestsPerSecond: 1}}); err == nil {
+		t.Fatal("expected an error")
+	}
+}
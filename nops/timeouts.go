@@ -0,0 +1,27 @@
+package nops
+
+import (
+	"context"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+)
+
+// Default operation timeouts of the resources, overridden with their timeouts block.
+const (
+	defaultCreateTimeout = 10 * time.Minute
+	defaultReadTimeout   = 5 * time.Minute
+	defaultUpdateTimeout = 10 * time.Minute
+	defaultDeleteTimeout = 10 * time.Minute
+)
+
+// withTimeout bounds ctx by an operation timeout of a timeouts block, such as
+// plan.Timeouts.Create, so API calls and retries stop at the operation deadline.
+func withTimeout(ctx context.Context, timeout func(context.Context, time.Duration) (time.Duration, diag.Diagnostics), def time.Duration, diags *diag.Diagnostics) (context.Context, context.CancelFunc) {
+	d, timeoutDiags := timeout(ctx, def)
+	diags.Append(timeoutDiags...)
+	if timeoutDiags.HasError() {
+		d = def
+	}
+	return context.WithTimeout(ctx, d)
+}
@@ -0,0 +1,99 @@
+package nops
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// TransportConfig - TLS and proxy settings for the client HTTP transport.
+type TransportConfig struct {
+	// CABundle, ClientCert and ClientKey accept either PEM contents or a path to a PEM file.
+	CABundle   string
+	ClientCert string
+	ClientKey  string
+	// ProxyURL overrides the HTTPS_PROXY/HTTP_PROXY environment variables when set.
+	ProxyURL string
+	// InsecureSkipVerify disables server certificate verification, only meant for local stand-ins.
+	InsecureSkipVerify bool
+}
+
+// NewTransport - builds an HTTP transport from the default one, applying the given TLS and proxy settings.
+func NewTransport(cfg TransportConfig) (*http.Transport, error) {
+	base, ok := http.DefaultTransport.(*http.Transport)
+	if !ok {
+		return nil, fmt.Errorf("unexpected default transport type %T", http.DefaultTransport)
+	}
+	transport := base.Clone()
+
+	tlsConfig := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		// Only ever enabled explicitly through the provider configuration.
+		InsecureSkipVerify: cfg.InsecureSkipVerify, //nolint:gosec
+	}
+
+	if cfg.CABundle != "" {
+		caPEM, err := readPEM(cfg.CABundle)
+		if err != nil {
+			return nil, fmt.Errorf("reading CA bundle: %w", err)
+		}
+
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(caPEM) {
+			return nil, errors.New("CA bundle does not contain any valid PEM certificate")
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if cfg.ClientCert != "" || cfg.ClientKey != "" {
+		if cfg.ClientCert == "" || cfg.ClientKey == "" {
+			return nil, errors.New("client certificate and client key must be provided together")
+		}
+
+		certPEM, err := readPEM(cfg.ClientCert)
+		if err != nil {
+			return nil, fmt.Errorf("reading client certificate: %w", err)
+		}
+		keyPEM, err := readPEM(cfg.ClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("reading client key: %w", err)
+		}
+
+		cert, err := tls.X509KeyPair(certPEM, keyPEM)
+		if err != nil {
+			return nil, fmt.Errorf("loading client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	transport.TLSClientConfig = tlsConfig
+
+	if cfg.ProxyURL != "" {
+		proxy, err := url.Parse(cfg.ProxyURL)
+		if err != nil {
+			return nil, fmt.Errorf("parsing proxy URL: %w", err)
+		}
+		if proxy.Scheme == "" || proxy.Host == "" {
+			return nil, fmt.Errorf("proxy URL %q must include a scheme and host, such as https://proxy.example.com:3128", cfg.ProxyURL)
+		}
+		transport.Proxy = http.ProxyURL(proxy)
+	}
+
+	return transport, nil
+}
+
+// readPEM returns value as is when it holds PEM contents, otherwise reads it as a file path.
+func readPEM(value string) ([]byte, error) {
+	if strings.Contains(value, "-----BEGIN") {
+		return []byte(value), nil
+	}
+	return os.ReadFile(value)
+}
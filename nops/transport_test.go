@@ -0,0 +1,64 @@
+package nops
+
+import (
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNewTransportTrustsCABundle(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	if err := os.WriteFile(caFile, caPEM, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, cfg := range map[string]TransportConfig{
+		"pem":      {CABundle: string(caPEM)},
+		"file":     {CABundle: caFile},
+		"insecure": {InsecureSkipVerify: true},
+	} {
+		t.Run(name, func(t *testing.T) {
+			transport, err := NewTransport(cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			res, err := (&http.Client{Transport: transport}).Get(server.URL)
+			if err != nil {
+				t.Fatalf("expected the server certificate to be trusted: %s", err)
+			}
+			res.Body.Close()
+		})
+	}
+
+	transport, err := NewTransport(TransportConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := (&http.Client{Transport: transport}).Get(server.URL); err == nil {
+		t.Fatal("expected the self-signed certificate to be rejected without a CA bundle")
+	}
+}
+
+func TestNewTransportRejectsInvalidSettings(t *testing.T) {
+	for name, cfg := range map[string]TransportConfig{
+		"cert without key": {ClientCert: "-----BEGIN CERTIFICATE-----"},
+		"invalid CA":       {CABundle: "-----BEGIN CERTIFICATE-----\nnot a cert\n-----END CERTIFICATE-----"},
+		"missing CA file":  {CABundle: filepath.Join(t.TempDir(), "missing.pem")},
+		"proxy no scheme":  {ProxyURL: "proxy.example.com:3128"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := NewTransport(cfg); err == nil {
+				t.Fatal("expected an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package nops
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+)
+
+// externalIDPattern follows the constraints of the sts:ExternalId condition key.
+var externalIDPattern = regexp.MustCompile(`^[\w+=,.@:/-]+$`)
+
+// IntegrationTrustPolicy - IAM trust policy allowing the nOps principal of
+// the deployment to assume the integration role with the given external ID.
+// Keys are sorted the same way as Terraform's jsonencode, so both produce the
+// same document.
+func IntegrationTrustPolicy(externalID string, d Deployment) (string, error) {
+	if len(externalID) < 2 || len(externalID) > 1224 || !externalIDPattern.MatchString(externalID) {
+		return "", fmt.Errorf("%q is not a valid external ID, it must be 2 to 1224 letters, digits or any of _+=,.@:/-", externalID)
+	}
+	if d.PrincipalAccountID == "" {
+		return "", fmt.Errorf("the nOps principal of the %q deployment is not known to this provider version", d.Name)
+	}
+
+	policy := map[string]any{
+		"Version": "2012-10-17",
+		"Statement": []any{
+			map[string]any{
+				"Effect": "Allow",
+				"Principal": map[string]any{
+					"AWS": fmt.Sprintf("arn:aws:iam::%s:root", d.PrincipalAccountID),
+				},
+				"Action": "sts:AssumeRole",
+				"Condition": map[string]any{
+					"StringEquals": map[string]any{
+						"sts:ExternalId": externalID,
+					},
+				},
+			},
+		},
+	}
+
+	raw, err := json.Marshal(policy)
+	if err != nil {
+		return "", err
+	}
+	return string(raw), nil
+}
@@ -0,0 +1,27 @@
+package nops
+
+import "testing"
+
+func TestIntegrationTrustPolicy(t *testing.T) {
+	us, _ := LookupDeployment("us")
+	policy, err := IntegrationTrustPolicy("a1b2c3d4-e5f6", us)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Same document as jsonencode of the trust policy in the onboarding examples.
+	want := `{"Statement":[{"Action":"sts:AssumeRole","Condition":{"StringEquals":{"sts:ExternalId":"a1b2c3d4-e5f6"}},"Effect":"Allow","Principal":{"AWS":"arn:aws:iam::202279780353:root"}}],"Version":"2012-10-17"}`
+	if policy != want {
+		t.Fatalf("unexpected policy:\n%s\nwant:\n%s", policy, want)
+	}
+
+	for name, externalID := range map[string]string{"empty": "", "too short": "a", "invalid characters": `id"}`} {
+		if _, err := IntegrationTrustPolicy(externalID, us); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+
+	if _, err := IntegrationTrustPolicy("a1b2c3d4-e5f6", Deployment{Name: "unknown"}); err == nil {
+		t.Error("expected an error for a deployment without a principal")
+	}
+}
@@ -0,0 +1,43 @@
+package nops
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"runtime"
+	"strings"
+)
+
+// DefaultUserAgent - User-Agent sent by a client created outside of the provider.
+const DefaultUserAgent string = "terraform-provider-nops"
+
+// UserAgent - structured User-Agent identifying the provider release, the
+// Terraform CLI version and the platform, followed by any products appended
+// with the TF_APPEND_USER_AGENT environment variable.
+func UserAgent(providerVersion, terraformVersion string) string {
+	if providerVersion == "" {
+		providerVersion = "dev"
+	}
+	if terraformVersion == "" {
+		terraformVersion = "unknown"
+	}
+
+	ua := fmt.Sprintf("%s/%s Terraform/%s (%s; %s)", DefaultUserAgent, providerVersion, terraformVersion, runtime.GOOS, runtime.GOARCH)
+	if extra := strings.TrimSpace(os.Getenv("TF_APPEND_USER_AGENT")); extra != "" {
+		ua += " " + extra
+	}
+	return ua
+}
+
+// setClientHeaders identifies the provider and the optional caller on the request.
+func (c *Client) setClientHeaders(req *http.Request) {
+	userAgent := c.UserAgent
+	if userAgent == "" {
+		userAgent = DefaultUserAgent
+	}
+	req.Header.Set("User-Agent", userAgent)
+
+	if c.Caller != "" {
+		req.Header.Set("X-Nops-Caller", c.Caller)
+	}
+}
@@ -0,0 +1,47 @@
+package nops
+
+import (
+	"context"
+	"net/http"
+	"runtime"
+	"testing"
+)
+
+func TestUserAgent(t *testing.T) {
+	t.Setenv("TF_APPEND_USER_AGENT", "")
+	want := "terraform-provider-nops/1.2.3 Terraform/1.9.5 (" + runtime.GOOS + "; " + runtime.GOARCH + ")"
+	if got := UserAgent("1.2.3", "1.9.5"); got != want {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+
+	t.Setenv("TF_APPEND_USER_AGENT", "acme-ci/2.0")
+	want = "terraform-provider-nops/dev Terraform/unknown (" + runtime.GOOS + "; " + runtime.GOARCH + ") acme-ci/2.0"
+	if got := UserAgent("", ""); got != want {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+}
+
+func TestClientSendsIdentificationHeaders(t *testing.T) {
+	var userAgent, caller string
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		userAgent, caller = r.Header.Get("User-Agent"), r.Header.Get("X-Nops-Caller")
+		_, _ = w.Write([]byte(`[]`))
+	})
+
+	ctx := context.Background()
+	if _, err := client.GetContainerCostBucketSetupStatus(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if userAgent != DefaultUserAgent || caller != "" {
+		t.Fatalf("unexpected default headers: User-Agent %q, X-Nops-Caller %q", userAgent, caller)
+	}
+
+	client.UserAgent = UserAgent("1.2.3", "1.9.5")
+	client.Caller = "platform-team/ci"
+	if _, err := client.GetContainerCostBucketSetupStatus(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if userAgent != client.UserAgent || caller != client.Caller {
+		t.Fatalf("unexpected headers: User-Agent %q, X-Nops-Caller %q", userAgent, caller)
+	}
+}
@@ -0,0 +1,53 @@
+package nops
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+)
+
+// awsAccountIDValidator accepts 12 digit AWS account IDs.
+func awsAccountIDValidator() validator.String {
+	return stringvalidator.RegexMatches(awsAccountIDPattern, "must be a 12 digit AWS account ID")
+}
+
+// masterPayerValidator accepts AWS account IDs and the NotApplicable sentinel
+// used for accounts outside of an organization.
+func masterPayerValidator() validator.String {
+	return stringvalidator.Any(
+		stringvalidator.OneOf(NotApplicable),
+		awsAccountIDValidator(),
+	)
+}
+
+// iamRoleARNValidator accepts IAM role ARNs such as arn:aws:iam::123456789012:role/nops.
+func iamRoleARNValidator() validator.String {
+	return stringvalidator.RegexMatches(iamRoleARNPattern, "must be an IAM role ARN such as arn:aws:iam::123456789012:role/nops-integration")
+}
+
+// bucketNameValidator accepts S3 bucket names and the NotApplicable sentinel.
+func bucketNameValidator() validator.String {
+	return stringvalidator.Any(
+		stringvalidator.OneOf(NotApplicable),
+		stringvalidator.RegexMatches(bucketNamePattern, "must be 3 to 63 lowercase letters, digits, dots or hyphens, starting and ending with a letter or digit"),
+	)
+}
+
+// projectNameValidators enforce the nOps project name rules.
+func projectNameValidators() []validator.String {
+	return []validator.String{
+		stringvalidator.UTF8LengthBetween(1, maxProjectNameLength),
+		stringvalidator.RegexMatches(projectNamePattern, "must not contain control characters or start or end with whitespace"),
+	}
+}
+
+// roleARNAccountMismatch describes a role ARN that doesn't belong to accountID,
+// empty when they match or the ARN is invalid, which is reported separately.
+func roleARNAccountMismatch(roleARN, accountID string) string {
+	arnAccountID, ok := roleARNAccountID(roleARN)
+	if !ok || arnAccountID == accountID {
+		return ""
+	}
+	return fmt.Sprintf("The role %s belongs to AWS account %s, but aws_account_id is %s. The role must be created in the integrated account.", roleARN, arnAccountID, accountID)
+}
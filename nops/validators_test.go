@@ -0,0 +1,99 @@
+package nops
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func validateString(v validator.String, value string) bool {
+	resp := &validator.StringResponse{}
+	v.ValidateString(context.Background(), validator.StringRequest{
+		Path:        path.Root("test"),
+		ConfigValue: types.StringValue(value),
+	}, resp)
+	return !resp.Diagnostics.HasError()
+}
+
+func TestValidators(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		validator validator.String
+		valid     []string
+		invalid   []string
+	}{
+		{
+			name:      "account ID",
+			validator: awsAccountIDValidator(),
+			valid:     []string{"123456789012"},
+			invalid:   []string{"", "12345678901", "1234567890123", "xxxxxxxxxxxx"},
+		},
+		{
+			name:      "master payer",
+			validator: masterPayerValidator(),
+			valid:     []string{"123456789012", "na"},
+			invalid:   []string{"", "NA", "12345678901"},
+		},
+		{
+			name:      "role ARN",
+			validator: iamRoleARNValidator(),
+			valid:     []string{"arn:aws:iam::123456789012:role/nops", "arn:aws-us-gov:iam::123456789012:role/path/to/nops-integration"},
+			invalid:   []string{"nops", "arn:aws:iam::1234:role/nops", "arn:aws:iam::123456789012:user/nops", "arn:aws:s3:::bucket"},
+		},
+		{
+			name:      "bucket name",
+			validator: bucketNameValidator(),
+			valid:     []string{"na", "nops-1-2-471112641702", "my.bucket"},
+			invalid:   []string{"", "NA", "my_bucket", "-bucket"},
+		},
+	} {
+		for _, value := range tc.valid {
+			if !validateString(tc.validator, value) {
+				t.Errorf("%s: expected %q to be valid", tc.name, value)
+			}
+		}
+		for _, value := range tc.invalid {
+			if validateString(tc.validator, value) {
+				t.Errorf("%s: expected %q to be invalid", tc.name, value)
+			}
+		}
+	}
+}
+
+func TestProjectNameValidators(t *testing.T) {
+	valid := func(name string) bool {
+		for _, v := range projectNameValidators() {
+			if !validateString(v, name) {
+				return false
+			}
+		}
+		return true
+	}
+
+	for _, name := range []string{"prod-payer", "a", "Team (EU) / billing"} {
+		if !valid(name) {
+			t.Errorf("expected %q to be valid", name)
+		}
+	}
+	for _, name := range []string{"", " prod", "prod ", "prod\nbilling", strings.Repeat("a", maxProjectNameLength+1)} {
+		if valid(name) {
+			t.Errorf("expected %q to be invalid", name)
+		}
+	}
+}
+
+func TestRoleARNAccountMismatch(t *testing.T) {
+	if msg := roleARNAccountMismatch("arn:aws:iam::123456789012:role/nops", "123456789012"); msg != "" {
+		t.Errorf("unexpected mismatch: %s", msg)
+	}
+	if msg := roleARNAccountMismatch("arn:aws:iam::210987654321:role/nops", "123456789012"); !strings.Contains(msg, "210987654321") {
+		t.Errorf("expected a mismatch, got %q", msg)
+	}
+	if msg := roleARNAccountMismatch("not-an-arn", "123456789012"); msg != "" {
+		t.Errorf("invalid ARNs are reported by the attribute validator, got %q", msg)
+	}
+}